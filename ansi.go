@@ -4,9 +4,52 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/KennethanCeyer/ptyx/vtparse"
+)
+
+const (
+	ESC = "\x1b"
+	ST  = ESC + "\\"
+	BEL = "\a"
+)
+
+const (
+	SGRReset     = 0
+	SGRBold      = 1
+	SGRFaint     = 2
+	SGRItalic    = 3
+	SGRUnderline = 4
+	SGRBlink     = 5
+	SGRReverse   = 7
+	SGRHidden    = 8
+	SGRStrike    = 9
+)
+
+const (
+	ModeCursorKeys     = 1
+	ModeOrigin         = 6
+	ModeAutoWrap       = 7
+	ModeMouseX10       = 9
+	ModeCursorVisible  = 25
+	ModeMouseNormal    = 1000
+	ModeMouseButton    = 1002
+	ModeMouseAny       = 1003
+	ModeFocus          = 1004
+	ModeMouseUTF8      = 1005
+	ModeMouseSGR       = 1006
+	ModeMouseURXVT     = 1015
+	ModeAltScreen      = 1049
+	ModeBracketedPaste = 2004
+)
+
+const (
+	DECSC = ESC + "7"
+	DECRC = ESC + "8"
 )
 
-func CSI(seq string) string { return "\x1b[" + seq }
+func CSI(seq string) string   { return "\x1b[" + seq }
 func CUP(row, col int) string { return CSI(fmt.Sprintf("%d;%dH", row, col)) }
 func SGR(codes ...int) string {
 	if len(codes) == 0 {
@@ -18,3 +61,334 @@ func SGR(codes ...int) string {
 	}
 	return CSI(strings.Join(s, ";") + "m")
 }
+
+func CUU(n int) string   { return CSI(strconv.Itoa(n) + "A") }
+func CUD(n int) string   { return CSI(strconv.Itoa(n) + "B") }
+func CUF(n int) string   { return CSI(strconv.Itoa(n) + "C") }
+func CUB(n int) string   { return CSI(strconv.Itoa(n) + "D") }
+func CNL(n int) string   { return CSI(strconv.Itoa(n) + "E") }
+func CPL(n int) string   { return CSI(strconv.Itoa(n) + "F") }
+func CHA(col int) string { return CSI(strconv.Itoa(col) + "G") }
+func VPA(row int) string { return CSI(strconv.Itoa(row) + "d") }
+
+// ED erases in display: 0 to the end, 1 to the start, 2 the whole screen,
+// 3 the scrollback.
+func ED(mode int) string { return CSI(strconv.Itoa(mode) + "J") }
+
+// EL erases in line: 0 to the end, 1 to the start, 2 the whole line.
+func EL(mode int) string { return CSI(strconv.Itoa(mode) + "K") }
+
+func ECH(n int) string { return CSI(strconv.Itoa(n) + "X") }
+func SU(n int) string  { return CSI(strconv.Itoa(n) + "S") }
+func SD(n int) string  { return CSI(strconv.Itoa(n) + "T") }
+
+func DECSTBM(top, bottom int) string { return CSI(fmt.Sprintf("%d;%dr", top, bottom)) }
+func ResetScrollRegion() string      { return CSI("r") }
+
+func DECSET(modes ...int) string { return CSI("?" + joinInts(modes) + "h") }
+func DECRST(modes ...int) string { return CSI("?" + joinInts(modes) + "l") }
+
+func Fg256(n int) string       { return SGR(38, 5, n) }
+func Bg256(n int) string       { return SGR(48, 5, n) }
+func FgRGB(r, g, b int) string { return SGR(38, 2, r, g, b) }
+func BgRGB(r, g, b int) string { return SGR(48, 2, r, g, b) }
+
+func OSC(seq string) string        { return "\x1b]" + seq + ST }
+func SetTitle(title string) string { return OSC("2;" + title) }
+
+// Hyperlink wraps text in an OSC 8 hyperlink. params are optional
+// key=value pairs such as "id=1".
+func Hyperlink(url, text string, params ...string) string {
+	return OSC("8;"+strings.Join(params, ":")+";"+url) + text + OSC("8;;")
+}
+
+func joinInts(v []int) string {
+	s := make([]string, len(v))
+	for i, n := range v {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ";")
+}
+
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},
+	{0x231a, 0x231b},
+	{0x2329, 0x232a},
+	{0x23e9, 0x23ec},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xa960, 0xa97f},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x16fe0, 0x16fe4},
+	{0x17000, 0x18cff},
+	{0x1b000, 0x1b2ff},
+	{0x1f004, 0x1f004},
+	{0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e},
+	{0x1f191, 0x1f19a},
+	{0x1f1e6, 0x1f1ff},
+	{0x1f200, 0x1f251},
+	{0x1f300, 0x1f64f},
+	{0x1f680, 0x1f6ff},
+	{0x1f7e0, 0x1f7eb},
+	{0x1f90c, 0x1f9ff},
+	{0x1fa70, 0x1faff},
+	{0x20000, 0x3fffd},
+}
+
+// RuneWidth returns the number of terminal cells r occupies: 0 for
+// controls, combining marks and format characters, 2 for East Asian wide
+// and emoji characters, 1 otherwise.
+func RuneWidth(r rune) int {
+	if r < 0x20 || (r >= 0x7f && r < 0xa0) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		m := (lo + hi) / 2
+		switch {
+		case r < wideRanges[m][0]:
+			hi = m
+		case r > wideRanges[m][1]:
+			lo = m + 1
+		default:
+			return 2
+		}
+	}
+	return 1
+}
+
+type ansiToken struct {
+	raw   string
+	kind  vtparse.Kind
+	width int
+	space bool
+	sgr   bool
+	reset bool
+	link  int
+}
+
+func tokenizeANSI(s string) []ansiToken {
+	var (
+		p      vtparse.Parser
+		toks   []ansiToken
+		start  int
+		prevRI bool
+		zwj    bool
+	)
+	for i := 0; i < len(s); i++ {
+		p.Parse([]byte{s[i]}, func(e vtparse.Event) {
+			end := i + 1
+			t := ansiToken{kind: e.Kind}
+			switch e.Kind {
+			case vtparse.KindPrint:
+				t.width = RuneWidth(e.Rune)
+				isRI := e.Rune >= 0x1f1e6 && e.Rune <= 0x1f1ff
+				if zwj || (isRI && prevRI) {
+					t.width = 0
+				}
+				prevRI = isRI && !prevRI
+				zwj = e.Rune == 0x200d
+				t.space = e.Rune == ' '
+			case vtparse.KindCSI:
+				if e.Final == 'm' && e.Private == 0 && len(e.Intermediates) == 0 {
+					t.sgr = true
+					t.reset = e.Params.Get(0, 0) == 0
+				}
+			case vtparse.KindOSC, vtparse.KindDCS, vtparse.KindSOS, vtparse.KindPM, vtparse.KindAPC:
+				if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+					end++
+				}
+				if e.Kind == vtparse.KindOSC && strings.HasPrefix(string(e.Data), "8;") {
+					t.link = -1
+					if _, url, _ := strings.Cut(string(e.Data[2:]), ";"); url != "" {
+						t.link = 1
+					}
+				}
+			}
+			if e.Kind != vtparse.KindPrint {
+				prevRI, zwj = false, false
+			}
+			t.raw = s[start:end]
+			start = end
+			toks = append(toks, t)
+		})
+	}
+	if start < len(s) {
+		toks = append(toks, ansiToken{raw: s[start:]})
+	}
+	return toks
+}
+
+// sgrState tracks the SGR sequences in effect since the last reset.
+type sgrState []string
+
+func (st sgrState) apply(t ansiToken) sgrState {
+	if !t.sgr {
+		return st
+	}
+	if t.reset {
+		st = nil
+		if t.raw == CSI("m") || t.raw == CSI("0m") {
+			return st
+		}
+	}
+	return append(st[:len(st):len(st)], t.raw)
+}
+
+// StripANSI removes escape sequences from s. C0 controls such as tab and
+// newline are kept; DEL and C1 controls are dropped.
+func StripANSI(s string) string {
+	var p vtparse.Parser
+	var b strings.Builder
+	p.Parse([]byte(s), func(e vtparse.Event) {
+		switch {
+		case e.Kind == vtparse.KindPrint:
+			b.WriteRune(e.Rune)
+		case e.Kind == vtparse.KindExecute && e.Byte < 0x80:
+			b.WriteByte(e.Byte)
+		}
+	})
+	return b.String()
+}
+
+// DisplayWidth returns the number of cells s occupies, ignoring escape
+// sequences. Controls count as zero width, tab included, so expand tabs
+// first where they matter; Truncate and Wrap count the same way.
+func DisplayWidth(s string) int {
+	w := 0
+	for _, t := range tokenizeANSI(s) {
+		w += t.width
+	}
+	return w
+}
+
+// Truncate shortens s to at most width cells, appending tail when anything
+// was cut. Escape sequences are kept and any SGR state or hyperlink still
+// open at the cut is closed.
+func Truncate(s string, width int, tail string) string {
+	toks := tokenizeANSI(s)
+	total := 0
+	for _, t := range toks {
+		total += t.width
+	}
+	if total <= width {
+		return s
+	}
+
+	limit := width - DisplayWidth(tail)
+	var (
+		b    strings.Builder
+		st   sgrState
+		link bool
+		w    int
+	)
+	for _, t := range toks {
+		if t.kind == vtparse.KindPrint {
+			if w+t.width > limit {
+				break
+			}
+			w += t.width
+		}
+		b.WriteString(t.raw)
+		st = st.apply(t)
+		if t.link != 0 {
+			link = t.link > 0
+		}
+	}
+	if limit >= 0 {
+		b.WriteString(tail)
+	}
+	if link {
+		b.WriteString(OSC("8;;"))
+	}
+	if len(st) > 0 {
+		b.WriteString(SGR())
+	}
+	return b.String()
+}
+
+// Wrap breaks s into lines of at most width cells, preferring to break at
+// spaces. SGR state active at a break is closed at the end of the line and
+// reopened at the start of the next one.
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	type item struct {
+		tok ansiToken
+		st  sgrState
+	}
+	var (
+		out     strings.Builder
+		line    []item
+		start   sgrState
+		st      sgrState
+		w       int
+		wrapped bool
+	)
+	flush := func(items []item, end sgrState, brk bool) {
+		for brk && len(items) > 0 && items[len(items)-1].tok.space {
+			items = items[:len(items)-1]
+		}
+		out.WriteString(strings.Join(start, ""))
+		for _, it := range items {
+			out.WriteString(it.tok.raw)
+		}
+		if brk {
+			if len(end) > 0 {
+				out.WriteString(SGR())
+			}
+			out.WriteByte('\n')
+		}
+	}
+	lastSpace := func() int {
+		for i := len(line) - 1; i >= 0; i-- {
+			if line[i].tok.space {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, t := range tokenizeANSI(s) {
+		if t.kind == vtparse.KindExecute && strings.HasSuffix(t.raw, "\n") {
+			flush(line, nil, false)
+			out.WriteString(t.raw)
+			line, start, w, wrapped = nil, nil, 0, false
+			continue
+		}
+		st = st.apply(t)
+		if t.width > 0 && w+t.width > width {
+			if i := lastSpace(); i >= 0 {
+				rest := append([]item(nil), line[i+1:]...)
+				at := line[i].st
+				flush(line[:i], at, true)
+				line, start, w = rest, at, 0
+				for _, it := range rest {
+					w += it.tok.width
+				}
+			}
+			if w > 0 && w+t.width > width {
+				flush(line, st, true)
+				line, start, w = nil, st, 0
+			}
+			wrapped = true
+		}
+		if t.space && w == 0 && wrapped {
+			continue
+		}
+		line = append(line, item{tok: t, st: st})
+		w += t.width
+	}
+	flush(line, nil, false)
+	return out.String()
+}
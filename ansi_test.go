@@ -66,3 +66,183 @@ func TestSGR(t *testing.T) {
 		})
 	}
 }
+
+func TestSequenceBuilders(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"CUU", CUU(3), "\x1b[3A"},
+		{"CUD", CUD(1), "\x1b[1B"},
+		{"CUF", CUF(12), "\x1b[12C"},
+		{"CUB", CUB(2), "\x1b[2D"},
+		{"CNL", CNL(1), "\x1b[1E"},
+		{"CPL", CPL(4), "\x1b[4F"},
+		{"CHA", CHA(10), "\x1b[10G"},
+		{"VPA", VPA(5), "\x1b[5d"},
+		{"ED", ED(2), "\x1b[2J"},
+		{"EL", EL(0), "\x1b[0K"},
+		{"ECH", ECH(8), "\x1b[8X"},
+		{"SU", SU(2), "\x1b[2S"},
+		{"SD", SD(3), "\x1b[3T"},
+		{"DECSTBM", DECSTBM(2, 20), "\x1b[2;20r"},
+		{"ResetScrollRegion", ResetScrollRegion(), "\x1b[r"},
+		{"DECSC", DECSC, "\x1b7"},
+		{"DECRC", DECRC, "\x1b8"},
+		{"DECSET alt screen", DECSET(ModeAltScreen), "\x1b[?1049h"},
+		{"DECRST cursor", DECRST(ModeCursorVisible), "\x1b[?25l"},
+		{"DECSET mouse", DECSET(ModeMouseButton, ModeMouseSGR), "\x1b[?1002;1006h"},
+		{"DECSET paste and focus", DECSET(ModeBracketedPaste, ModeFocus), "\x1b[?2004;1004h"},
+		{"Fg256", Fg256(208), "\x1b[38;5;208m"},
+		{"Bg256", Bg256(17), "\x1b[48;5;17m"},
+		{"FgRGB", FgRGB(255, 128, 0), "\x1b[38;2;255;128;0m"},
+		{"BgRGB", BgRGB(0, 0, 0), "\x1b[48;2;0;0;0m"},
+		{"SGR constants", SGR(SGRBold, SGRUnderline), "\x1b[1;4m"},
+		{"OSC", OSC("52;c;aGk="), "\x1b]52;c;aGk=\x1b\\"},
+		{"SetTitle", SetTitle("ptyx"), "\x1b]2;ptyx\x1b\\"},
+		{"Hyperlink", Hyperlink("https://go.dev", "Go"), "\x1b]8;;https://go.dev\x1b\\Go\x1b]8;;\x1b\\"},
+		{"Hyperlink with params", Hyperlink("https://go.dev", "Go", "id=1"), "\x1b]8;id=1;https://go.dev\x1b\\Go\x1b]8;;\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q; want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want int
+	}{
+		{'a', 1},
+		{'\t', 0},
+		{0x7f, 0},
+		{'é', 1},
+		{0x301, 0},
+		{0x200d, 0},
+		{'世', 2},
+		{'한', 2},
+		{'Ａ', 2},
+		{'😀', 2},
+		{'─', 1},
+	}
+	for _, tt := range tests {
+		if got := RuneWidth(tt.r); got != tt.want {
+			t.Errorf("RuneWidth(%U) = %d; want %d", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Plain", "hello", "hello"},
+		{"SGR", SGR(SGRBold, 31) + "red" + SGR(), "red"},
+		{"Cursor and erase", CUP(1, 1) + ED(2) + "x" + EL(0), "x"},
+		{"Controls kept", "a\tb\r\n", "a\tb\r\n"},
+		{"C1 and DEL dropped", "a\u009bb\x7fc\u0085", "abc"},
+		{"Hyperlink", Hyperlink("https://go.dev", "Go"), "Go"},
+		{"OSC with BEL", "\x1b]0;title\x07text", "text"},
+		{"Private mode", DECSET(ModeAltScreen) + "tui", "tui"},
+		{"Wide", "\x1b[1m世界\x1b[0m", "世界"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripANSI(tt.in); got != tt.want {
+				t.Errorf("StripANSI(%q) = %q; want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"Empty", "", 0},
+		{"ASCII", "hello", 5},
+		{"Styled", SGR(1, 32) + "ok" + SGR(), 2},
+		{"East Asian wide", "日本語", 6},
+		{"Mixed", "a世b", 4},
+		{"Combining", "é", 1},
+		{"ZWJ emoji", "👩‍💻", 2},
+		{"Flag", "🇰🇷", 2},
+		{"Variation selector", "❤️", 1},
+		{"Hyperlink", Hyperlink("https://go.dev", "Go"), 2},
+		{"Tab", "a\tb", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DisplayWidth(tt.in); got != tt.want {
+				t.Errorf("DisplayWidth(%q) = %d; want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		tail  string
+		want  string
+	}{
+		{"Fits", "hello", 5, "…", "hello"},
+		{"Plain", "hello world", 8, "…", "hello w…"},
+		{"No tail", "hello", 3, "", "hel"},
+		{"Closes SGR", SGR(31) + "red text", 5, "…", SGR(31) + "red …" + SGR()},
+		{"Reset before cut", SGR(31) + "ab" + SGR() + "cdef", 4, "", SGR(31) + "ab" + SGR() + "cd"},
+		{"Wide not split", "a世界", 4, "", "a世"},
+		{"Wide with tail", "世界世界", 5, "…", "世界…"},
+		{"Closes hyperlink", Hyperlink("https://go.dev", "golang"), 3, "", "\x1b]8;;https://go.dev\x1b\\gol\x1b]8;;\x1b\\"},
+		{"Keeps combining", "ééé", 2, "", "éé"},
+		{"Tail wider than width", "hello", 1, "...", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.in, tt.width, tt.tail)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d, %q) = %q; want %q", tt.in, tt.width, tt.tail, got, tt.want)
+			}
+			if w := DisplayWidth(got); w > tt.width {
+				t.Errorf("DisplayWidth(result) = %d; exceeds %d", w, tt.width)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{"Fits", "hello", 10, "hello"},
+		{"Words", "the quick brown fox", 10, "the quick\nbrown fox"},
+		{"Long word", "abcdefghij", 4, "abcd\nefgh\nij"},
+		{"Existing newline", "ab\ncd ef", 4, "ab\ncd\nef"},
+		{"Reopens SGR", SGR(1) + "aaa bbb" + SGR(), 4, SGR(1) + "aaa" + SGR() + "\n" + SGR(1) + "bbb" + SGR()},
+		{"Style starts after break", "aaa " + SGR(32) + "bbb", 4, "aaa\n" + SGR(32) + "bbb"},
+		{"Wide", "世界世界", 5, "世界\n世界"},
+		{"Zero width", "abc", 0, "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Wrap(tt.in, tt.width); got != tt.want {
+				t.Errorf("Wrap(%q, %d) = %q; want %q", tt.in, tt.width, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package ptyx
 
 import (
+	"context"
 	"errors"
+	"image/color"
 	"io"
 	"os"
+	"time"
 )
 
 type Console interface {
@@ -12,11 +15,33 @@ type Console interface {
 	Err() *os.File
 	IsATTYOut() bool
 	Size() (int, int)
+	SizeInfo() Size
 	MakeRaw() (RawState, error)
 	Restore(RawState) error
 	EnableVT()
 	OnResize() <-chan struct{}
 	Close() error
+
+	// Queries ask the terminal and wait up to timeout for its reply. They
+	// put the console in raw mode for the duration if it is not already, and
+	// return ErrNoReply when the terminal ignores the request.
+	PrimaryDeviceAttributes(timeout time.Duration) (PrimaryAttributes, error)
+	SecondaryDeviceAttributes(timeout time.Duration) (SecondaryAttributes, error)
+	TerminalVersion(timeout time.Duration) (string, error)
+	ModeStatus(mode int, timeout time.Duration) (ModeState, error)
+	ForegroundColor(timeout time.Duration) (color.RGBA, error)
+	BackgroundColor(timeout time.Duration) (color.RGBA, error)
+	CursorPosition(timeout time.Duration) (row, col int, err error)
+
+	// Input modes are turned off again by Restore and Close.
+	EnableKittyKeyboard(flags KittyFlags) error
+	DisableKittyKeyboard() error
+	EnableMouse(t MouseTracking, e MouseEncoding) error
+	DisableMouse() error
+	EnableBracketedPaste() error
+	DisableBracketedPaste() error
+	EnableFocusEvents() error
+	DisableFocusEvents() error
 }
 
 func IsErrNotAConsole(err error) bool { return errors.Is(err, ErrNotAConsole) }
@@ -27,11 +52,31 @@ type Session interface {
 	PtyReader() io.Reader
 	PtyWriter() io.Writer
 	Resize(cols, rows int) error
+	ResizeWithPixels(cols, rows, xpixel, ypixel int) error
+	// SendKeys writes keys, each a Key, KeyEvent, rune or string, encoded
+	// for the KeyModes the program has set, as tracked with
+	// SpawnOpts.TrackKeyModes.
+	SendKeys(keys ...any) error
+	// KeyModes reports the modes set in the output read so far from
+	// PtyReader. Without SpawnOpts.TrackKeyModes they are never tracked and
+	// KeyModes returns the zero value.
+	KeyModes() KeyModes
 	Wait() error
 	Kill() error
 	Close() error
 	Pid() int
 	CloseStdin() error
+	SendEOF() error
+	Signal(sig os.Signal) error
+	SignalForeground(sig os.Signal) error
+	Terminate(ctx context.Context, p TerminatePolicy) (TerminateStep, error)
+	Termios() (Termios, error)
+	// SetTermios applies every field of t; change a copy of what Termios
+	// returns rather than building one from scratch.
+	SetTermios(t Termios) error
+	StdinPipe() io.WriteCloser
+	StdoutPipe() io.ReadCloser
+	StderrPipe() io.ReadCloser
 }
 
 type SpawnOpts struct {
@@ -41,6 +86,52 @@ type SpawnOpts struct {
 	Dir  string
 	Cols int
 	Rows int
+	// XPixel and YPixel set the initial window size in pixels, for programs
+	// that draw images. They are ignored on Windows.
+	XPixel int
+	YPixel int
+
+	// StdinMode, StdoutMode and StderrMode choose between the PTY and a pipe
+	// for each standard stream. Setting Stdin, Stdout or Stderr instead
+	// connects that stream to the given reader or writer; an *os.File is
+	// handed to the child directly.
+	StdinMode  StreamMode
+	StdoutMode StreamMode
+	StderrMode StreamMode
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+
+	// PtmxPath selects the ptmx device to allocate the PTY from, for example
+	// the ptmx node of a separate devpts mount. Only Linux supports paths
+	// other than /dev/ptmx.
+	PtmxPath string
+
+	// Termios, if set, is applied to the PTY before the child starts. Every
+	// field is applied, so start from DefaultTermios and change what you
+	// need; a Termios with only Echo false also turns off canonical mode,
+	// signals and the special characters.
+	Termios *Termios
+
+	// TerminatePolicy, if set, makes Run and RunInteractive stop the process
+	// with Session.Terminate when their context is done instead of killing it.
+	TerminatePolicy *TerminatePolicy
+
+	// KillProcessTree makes Kill and Close kill every process in the
+	// session started for the child, including orphaned background jobs,
+	// instead of only the child itself. Jobs still running when the child
+	// exits are killed by Close, even after Wait.
+	KillProcessTree bool
+
+	// TrackKeyModes makes the session follow the KeyModes the program sets,
+	// for SendKeys and KeyModes. PtyReader then parses all output as it is
+	// read and returns a plain io.Reader rather than the *os.File of the
+	// PTY, so read deadlines and io.Copy's fast paths are lost.
+	TrackKeyModes bool
+	// Subreaper makes this process a child subreaper (Linux only), so
+	// descendants orphaned by the child are reparented to it and are reaped
+	// after being killed. It affects the whole process and is never undone.
+	Subreaper bool
 }
 
 type Mux interface {
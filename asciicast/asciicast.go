@@ -0,0 +1,163 @@
+// Package asciicast reads and writes asciicast v2 recordings and records or
+// replays ptyx sessions in that format.
+package asciicast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+const Version = 2
+
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+	EventMarker = "m"
+)
+
+var ErrVersion = errors.New("asciicast: unsupported version")
+
+type Header struct {
+	Version       int               `json:"version"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Timestamp     int64             `json:"timestamp,omitempty"`
+	Duration      float64           `json:"duration,omitempty"`
+	IdleTimeLimit float64           `json:"idle_time_limit,omitempty"`
+	Command       string            `json:"command,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+}
+
+// Event is one line of the event stream. Time is in seconds since the start
+// of the recording.
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('[')
+	b.WriteString(strconv.FormatFloat(e.Time, 'f', 6, 64))
+	b.WriteString(", ")
+	if err := writeJSONString(&b, e.Type); err != nil {
+		return nil, err
+	}
+	b.WriteString(", ")
+	if err := writeJSONString(&b, e.Data); err != nil {
+		return nil, err
+	}
+	b.WriteByte(']')
+	return b.Bytes(), nil
+}
+
+func (e *Event) UnmarshalJSON(p []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(p, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("asciicast: event has %d fields, want 3", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &e.Data)
+}
+
+func writeJSONString(b *bytes.Buffer, s string) error {
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	b.Truncate(b.Len() - 1)
+	return nil
+}
+
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	if h.Version == 0 {
+		h.Version = Version
+	}
+	p, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(p, '\n')); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+func (w *Writer) WriteEvent(e Event) error {
+	p, err := e.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(append(p, '\n'))
+	return err
+}
+
+type Reader struct {
+	header Header
+	sc     *bufio.Scanner
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	var h Header
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, fmt.Errorf("asciicast: header: %w", err)
+	}
+	if h.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, h.Version)
+	}
+	return &Reader{header: h, sc: sc}, nil
+}
+
+func (r *Reader) Header() Header { return r.header }
+
+// Next returns the next event, or io.EOF at the end of the stream.
+func (r *Reader) Next() (Event, error) {
+	for r.sc.Scan() {
+		line := bytes.TrimSpace(r.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return Event{}, err
+		}
+		return e, nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
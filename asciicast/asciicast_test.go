@@ -0,0 +1,84 @@
+package asciicast
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriterReader_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	h := Header{Width: 80, Height: 24, Timestamp: 1700000000, Env: map[string]string{"TERM": "xterm-256color"}}
+	w, err := NewWriter(&buf, h)
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	events := []Event{
+		{Time: 0.1, Type: EventOutput, Data: "hello \x1b[1m<b>\x1b[0m\r\n"},
+		{Time: 0.25, Type: EventInput, Data: "ls\r"},
+		{Time: 1.5, Type: EventResize, Data: "100x30"},
+	}
+	for _, e := range events {
+		if err := w.WriteEvent(e); err != nil {
+			t.Fatalf("WriteEvent() failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != `{"version":2,"width":80,"height":24,"timestamp":1700000000,"env":{"TERM":"xterm-256color"}}` {
+		t.Errorf("header line = %s", lines[0])
+	}
+	if lines[1] != `[0.100000, "o", "hello \u001b[1m<b>\u001b[0m\r\n"]` {
+		t.Errorf("event line = %s", lines[1])
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	h.Version = Version
+	if got := r.Header(); !reflect.DeepEqual(got, h) {
+		t.Errorf("Header() = %+v, want %+v", got, h)
+	}
+	for _, want := range events {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Next() = %+v, want %+v", got, want)
+		}
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestNewReader_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Empty", ""},
+		{"BadHeader", "not json\n"},
+		{"WrongVersion", `{"version":1,"width":80,"height":24}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReader(strings.NewReader(tt.input)); err == nil {
+				t.Error("NewReader() should have failed")
+			}
+		})
+	}
+
+	r, err := NewReader(strings.NewReader(`{"version":2,"width":1,"height":1}` + "\n[1.0, \"o\"]\n"))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	if _, err := r.Next(); err == nil {
+		t.Error("Next() on malformed event should have failed")
+	}
+}
@@ -0,0 +1,74 @@
+package asciicast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx"
+)
+
+type PlayOpts struct {
+	// Speed scales playback; 2 plays twice as fast. Zero means 1.
+	Speed float64
+	// IdleTimeLimit caps pauses between events. Zero uses the header's
+	// idle_time_limit, if any.
+	IdleTimeLimit time.Duration
+}
+
+// Play writes the output events of the recording read from r to the
+// console, reproducing the recorded timing.
+func Play(ctx context.Context, c ptyx.Console, r io.Reader, opts PlayOpts) error {
+	cr, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	idle := opts.IdleTimeLimit
+	if idle <= 0 && cr.Header().IdleTimeLimit > 0 {
+		idle = time.Duration(cr.Header().IdleTimeLimit * float64(time.Second))
+	}
+
+	var prev float64
+	for {
+		e, err := cr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		d := time.Duration((e.Time - prev) * float64(time.Second))
+		prev = e.Time
+		if idle > 0 && d > idle {
+			d = idle
+		}
+		if err := sleep(ctx, time.Duration(float64(d)/speed)); err != nil {
+			return err
+		}
+		if e.Type != EventOutput {
+			continue
+		}
+		if _, err := io.WriteString(c.Out(), e.Data); err != nil {
+			return err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,54 @@
+package asciicast
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx/testptyx"
+)
+
+const cast = `{"version": 2, "width": 80, "height": 24, "idle_time_limit": 0.05}
+[0.01, "o", "hello "]
+[0.02, "i", "x"]
+[5.0, "o", "world"]
+[5.01, "r", "100x30"]
+`
+
+func TestPlay(t *testing.T) {
+	c := testptyx.NewMockConsole("")
+	start := time.Now()
+	if err := Play(context.Background(), c, strings.NewReader(cast), PlayOpts{Speed: 2}); err != nil {
+		t.Fatalf("Play() failed: %v", err)
+	}
+	if got := c.OutBuffer.String(); got != "hello world" {
+		t.Errorf("output = %q, want %q", got, "hello world")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Play() took %v, idle time limit not applied", elapsed)
+	}
+}
+
+func TestPlay_Cancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	c := testptyx.NewMockConsole("")
+	err := Play(ctx, c, strings.NewReader(cast), PlayOpts{IdleTimeLimit: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Play() error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := c.OutBuffer.String(); got != "hello " {
+		t.Errorf("output = %q, want %q", got, "hello ")
+	}
+}
+
+func TestPlay_WriteError(t *testing.T) {
+	c := testptyx.NewMockConsole("")
+	c.ForceWriteError = errors.New("write failed")
+	if err := Play(context.Background(), c, strings.NewReader(cast), PlayOpts{Speed: 100}); err == nil {
+		t.Fatal("Play() should propagate write errors")
+	}
+}
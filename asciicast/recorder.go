@@ -0,0 +1,158 @@
+package asciicast
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KennethanCeyer/ptyx"
+)
+
+// Recorder is a Session that records everything read from its PtyReader,
+// written to its PtyWriter and every Resize. Pass it wherever the wrapped
+// session would be used, for example to Mux.Start.
+type Recorder struct {
+	ptyx.Session
+	w     *Writer
+	start time.Time
+	now   func() time.Time
+	out   *recordReader
+	in    *recordWriter
+}
+
+// NewHeader describes the given console: its size, SHELL and TERM, and the
+// current time.
+func NewHeader(c ptyx.Console) Header {
+	w, h := c.Size()
+	env := map[string]string{}
+	for _, k := range []string{"SHELL", "TERM"} {
+		if v := os.Getenv(k); v != "" {
+			env[k] = v
+		}
+	}
+	return Header{
+		Version:   Version,
+		Width:     w,
+		Height:    h,
+		Timestamp: time.Now().Unix(),
+		Env:       env,
+	}
+}
+
+func Record(s ptyx.Session, w io.Writer, h Header) (*Recorder, error) {
+	return record(s, w, h, time.Now)
+}
+
+func record(s ptyx.Session, w io.Writer, h Header, now func() time.Time) (*Recorder, error) {
+	start := now()
+	if h.Timestamp == 0 {
+		h.Timestamp = start.Unix()
+	}
+	cw, err := NewWriter(w, h)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{Session: s, w: cw, start: start, now: now}
+	r.out = &recordReader{r: s.PtyReader(), rec: r}
+	r.in = &recordWriter{w: s.PtyWriter(), rec: r}
+	return r, nil
+}
+
+func (r *Recorder) PtyReader() io.Reader { return r.out }
+func (r *Recorder) PtyWriter() io.Writer { return r.in }
+
+// SendKeys sends keys through the recorder so they are recorded as input.
+func (r *Recorder) SendKeys(keys ...any) error {
+	b, err := ptyx.EncodeKeys(r.KeyModes(), keys...)
+	if err != nil {
+		return err
+	}
+	_, err = r.PtyWriter().Write(b)
+	return err
+}
+
+func (r *Recorder) Resize(cols, rows int) error {
+	if err := r.Session.Resize(cols, rows); err != nil {
+		return err
+	}
+	return r.event(EventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+func (r *Recorder) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	if err := r.Session.ResizeWithPixels(cols, rows, xpixel, ypixel); err != nil {
+		return err
+	}
+	return r.event(EventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+func (r *Recorder) Marker(label string) error { return r.event(EventMarker, label) }
+
+func (r *Recorder) event(typ, data string) error {
+	return r.w.WriteEvent(Event{Time: r.now().Sub(r.start).Seconds(), Type: typ, Data: data})
+}
+
+type recordReader struct {
+	r   io.Reader
+	rec *Recorder
+	buf utf8Buffer
+}
+
+func (rr *recordReader) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	if n > 0 {
+		if s := rr.buf.take(p[:n]); s != "" {
+			_ = rr.rec.event(EventOutput, s)
+		}
+	}
+	return n, err
+}
+
+type recordWriter struct {
+	w   io.Writer
+	rec *Recorder
+	buf utf8Buffer
+}
+
+func (rw *recordWriter) Write(p []byte) (int, error) {
+	n, err := rw.w.Write(p)
+	if n > 0 {
+		if s := rw.buf.take(p[:n]); s != "" {
+			_ = rw.rec.event(EventInput, s)
+		}
+	}
+	return n, err
+}
+
+// utf8Buffer holds back an incomplete trailing UTF-8 sequence so that a
+// character split across reads is recorded in one event.
+type utf8Buffer struct {
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (u *utf8Buffer) take(p []byte) string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	b := append(u.pending, p...)
+	n := completeUTF8(b)
+	u.pending = append([]byte(nil), b[n:]...)
+	return string(b[:n])
+}
+
+func completeUTF8(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax+1; i-- {
+		switch c := b[i]; {
+		case c < utf8.RuneSelf:
+			return len(b)
+		case utf8.RuneStart(c):
+			if utf8.FullRune(b[i:]) {
+				return len(b)
+			}
+			return i
+		}
+	}
+	return len(b)
+}
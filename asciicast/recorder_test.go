@@ -0,0 +1,115 @@
+package asciicast
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx/testptyx"
+)
+
+type chunkReader struct{ chunks [][]byte }
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestRecorder(t *testing.T) {
+	s := testptyx.NewMockSession("")
+	s.PtyOutReader = &chunkReader{chunks: [][]byte{[]byte("$ "), []byte("\xe4\xb8"), []byte("\x96\r\n")}}
+
+	clock := time.Unix(1700000000, 0)
+	now := func() time.Time {
+		clock = clock.Add(500 * time.Millisecond)
+		return clock
+	}
+
+	var buf bytes.Buffer
+	rec, err := record(s, &buf, Header{Width: 80, Height: 24}, now)
+	if err != nil {
+		t.Fatalf("record() failed: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, rec.PtyReader()); err != nil {
+		t.Fatalf("reading PtyReader failed: %v", err)
+	}
+	if _, err := rec.PtyWriter().Write([]byte("exit\r")); err != nil {
+		t.Fatalf("writing PtyWriter failed: %v", err)
+	}
+	if err := rec.Resize(120, 40); err != nil {
+		t.Fatalf("Resize() failed: %v", err)
+	}
+	if err := rec.Marker("done"); err != nil {
+		t.Fatalf("Marker() failed: %v", err)
+	}
+
+	if got := s.PtyInBuffer.String(); got != "exit\r" {
+		t.Errorf("session input = %q, want %q", got, "exit\r")
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	if got := r.Header().Timestamp; got != 1700000000 {
+		t.Errorf("Header().Timestamp = %d", got)
+	}
+	want := []Event{
+		{Time: 0.5, Type: EventOutput, Data: "$ "},
+		{Time: 1.0, Type: EventOutput, Data: "世\r\n"},
+		{Time: 1.5, Type: EventInput, Data: "exit\r"},
+		{Time: 2.0, Type: EventResize, Data: "120x40"},
+		{Time: 2.5, Type: EventMarker, Data: "done"},
+	}
+	for _, w := range want {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() failed: %v", err)
+		}
+		if got != w {
+			t.Errorf("Next() = %+v, want %+v", got, w)
+		}
+	}
+}
+
+func TestNewHeader(t *testing.T) {
+	t.Setenv("TERM", "xterm-test")
+	h := NewHeader(testptyx.NewMockConsole(""))
+	if h.Width != 80 || h.Height != 24 {
+		t.Errorf("size = %dx%d, want 80x24", h.Width, h.Height)
+	}
+	if h.Env["TERM"] != "xterm-test" {
+		t.Errorf("Env[TERM] = %q", h.Env["TERM"])
+	}
+	if _, ok := h.Env["SHELL"]; ok != (os.Getenv("SHELL") != "") {
+		t.Errorf("Env[SHELL] presence mismatch")
+	}
+	if h.Version != Version || h.Timestamp == 0 {
+		t.Errorf("header = %+v", h)
+	}
+}
+
+func TestCompleteUTF8(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abc", 3},
+		{"a\xe4\xb8", 1},
+		{"a\xe4\xb8\x96", 4},
+		{"\xf0\x9f\x98", 0},
+		{"\x80\x80", 2},
+	}
+	for _, tt := range tests {
+		if got := completeUTF8([]byte(tt.in)); got != tt.want {
+			t.Errorf("completeUTF8(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
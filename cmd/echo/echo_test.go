@@ -83,6 +83,39 @@ func TestEchoLoop(t *testing.T) {
 	}
 }
 
+func TestKeyLoop(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantOut string
+	}{
+		{"Keys", "a\x1b[1;5A\x1bOP", "key a\r\nkey ctrl+up\r\nkey f1\r\n"},
+		{"Ctrl+C terminates", "x\x03y", "key x\r\n"},
+		{"Kitty Ctrl+C with Caps Lock", "x\x1b[99;69uy", "key x\r\n"},
+		{"Paste and focus", "\x1b[I\x1b[200~a\rb\x1b[201~\x1b[O", "focus in=true\r\npaste \"a\\rb\"\r\nfocus in=false\r\n"},
+		{"Mouse", "\x1b[<0;3;4M", "mouse left press at 3,4\r\n"},
+		{"Empty input (EOF)", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			keyLoop(strings.NewReader(tt.input), &out, &errOut)
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("keyLoop() output = %q, want %q", got, tt.wantOut)
+			}
+			if errOut.Len() != 0 {
+				t.Errorf("keyLoop() error output = %q", errOut.String())
+			}
+		})
+	}
+
+	var out, errOut bytes.Buffer
+	keyLoop(&errorReader{}, &out, &errOut)
+	if got := errOut.String(); got != "read error: read failed\r\n" {
+		t.Errorf("keyLoop() error output = %q", got)
+	}
+}
+
 func TestEcho_HelperProcess(t *testing.T) {
 	if os.Getenv("PTYX_ECHO_HELPER") != "1" {
 		return
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 
 	"github.com/KennethanCeyer/ptyx"
 )
@@ -36,6 +37,34 @@ func echoLoop(in io.Reader, out io.Writer, errOut io.Writer) {
 	}
 }
 
+// keyLoop prints the decoded input events instead of raw bytes.
+func keyLoop(in io.Reader, out io.Writer, errOut io.Writer) {
+	ir := ptyx.NewInputReader(in)
+	for {
+		ev, err := ir.ReadEvent()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(errOut, "read error: %v\r\n", err)
+			}
+			return
+		}
+		switch ev := ev.(type) {
+		case ptyx.KeyEvent:
+			ev.Mods &^= ptyx.ModCapsLock | ptyx.ModNumLock
+			if ev == (ptyx.KeyEvent{Rune: 'c', Mods: ptyx.ModCtrl}) {
+				return
+			}
+			fmt.Fprintf(out, "key %s\r\n", ev)
+		case ptyx.MouseEvent:
+			fmt.Fprintf(out, "mouse %s\r\n", ev)
+		case ptyx.PasteEvent:
+			fmt.Fprintf(out, "paste %q\r\n", ev.Text)
+		case ptyx.FocusEvent:
+			fmt.Fprintf(out, "focus in=%t\r\n", ev.In)
+		}
+	}
+}
+
 func main() {
 	c, err := newConsoleFunc()
 	if err != nil {
@@ -52,5 +81,11 @@ func main() {
 	defer c.Restore(st)
 
 	fmt.Fprint(c.Out(), "Entering raw echo mode. Press Ctrl+C to exit.\r\n")
+	if slices.Contains(os.Args[1:], "-keys") {
+		_ = c.EnableBracketedPaste()
+		_ = c.EnableFocusEvents()
+		keyLoop(c.In(), c.Out(), c.Err())
+		return
+	}
 	echoLoop(c.In(), c.Out(), c.Err())
 }
@@ -42,12 +42,27 @@ func TestProcessStream(t *testing.T) {
 		{
 			name:  "Incomplete CSI at EOF",
 			input: "text\x1b[1;31",
-			want:  "[EVENT:TEXT] \"text\"\n[EVENT:ANSI] \"1;31\"\n",
+			want:  "[EVENT:TEXT] \"text\"\n",
 		},
 		{
-			name:  "Non-CSI escape sequence",
-			input: "\x1b]",
-			want:  "[EVENT:UNHANDLED] \"]\"\n",
+			name:  "ESC sequence",
+			input: "\x1b7a\x1b(0",
+			want:  "[EVENT:ESC] \"7\"\n[EVENT:TEXT] \"a\"\n[EVENT:ESC] \"(0\"\n",
+		},
+		{
+			name:  "OSC sequence",
+			input: "\x1b]0;title\x07x",
+			want:  "[EVENT:OSC] \"0;title\"\n[EVENT:TEXT] \"x\"\n",
+		},
+		{
+			name:  "DCS and APC sequences",
+			input: "\x1bP1$r0m\x1b\\\x1b_Gq\x1b\\",
+			want:  "[EVENT:DCS] \"1$r0m\"\n[EVENT:STRING] \"APC Gq\"\n",
+		},
+		{
+			name:  "Other control characters",
+			input: "a\tb\x07",
+			want:  "[EVENT:TEXT] \"a\"\n[EVENT:CONTROL] \"\\t\"\n[EVENT:TEXT] \"b\"\n[EVENT:CONTROL] \"\\a\"\n",
 		},
 		{
 			name:  "Escape at EOF",
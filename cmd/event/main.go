@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -12,6 +11,7 @@ import (
 	"runtime"
 
 	"github.com/KennethanCeyer/ptyx"
+	"github.com/KennethanCeyer/ptyx/vtparse"
 )
 
 var (
@@ -23,10 +23,13 @@ var (
 type EventType string
 
 const (
-	EventText      EventType = "TEXT"
-	EventANSI      EventType = "ANSI"
-	EventControl   EventType = "CONTROL"
-	EventUnhandled EventType = "UNHANDLED"
+	EventText    EventType = "TEXT"
+	EventANSI    EventType = "ANSI"
+	EventControl EventType = "CONTROL"
+	EventESC     EventType = "ESC"
+	EventOSC     EventType = "OSC"
+	EventDCS     EventType = "DCS"
+	EventString  EventType = "STRING"
 )
 
 type Event struct {
@@ -84,53 +87,45 @@ func main() {
 }
 
 func processStream(w io.Writer, r io.Reader) {
-	reader := bufio.NewReader(r)
+	var p vtparse.Parser
 	var textBuffer bytes.Buffer
 
+	flush := func() {
+		if textBuffer.Len() > 0 {
+			fmt.Fprintln(w, Event{Type: EventText, Payload: textBuffer.String()})
+			textBuffer.Reset()
+		}
+	}
+	emit := func(e vtparse.Event) {
+		if e.Kind == vtparse.KindPrint {
+			textBuffer.WriteRune(e.Rune)
+			return
+		}
+		flush()
+		seq := e.Sequence()
+		switch e.Kind {
+		case vtparse.KindExecute:
+			fmt.Fprintln(w, Event{Type: EventControl, Payload: seq})
+		case vtparse.KindCSI:
+			fmt.Fprintln(w, Event{Type: EventANSI, Payload: seq[2:]})
+		case vtparse.KindESC:
+			fmt.Fprintln(w, Event{Type: EventESC, Payload: seq[1:]})
+		case vtparse.KindOSC:
+			fmt.Fprintln(w, Event{Type: EventOSC, Payload: string(e.Data)})
+		case vtparse.KindDCS:
+			fmt.Fprintln(w, Event{Type: EventDCS, Payload: seq[2 : len(seq)-2]})
+		default:
+			fmt.Fprintln(w, Event{Type: EventString, Payload: e.Kind.String() + " " + string(e.Data)})
+		}
+	}
+
+	buf := make([]byte, 4096)
 	for {
-		r, _, err := reader.ReadRune()
+		n, err := r.Read(buf)
+		p.Parse(buf[:n], emit)
 		if err != nil {
-			if err == io.EOF && textBuffer.Len() > 0 {
-				fmt.Fprintln(w, Event{Type: EventText, Payload: textBuffer.String()})
-			}
 			break
 		}
-
-		switch r {
-		case '\x1b':
-			if textBuffer.Len() > 0 {
-				fmt.Fprintln(w, Event{Type: EventText, Payload: textBuffer.String()})
-				textBuffer.Reset()
-			}
-
-			next, _, err := reader.ReadRune()
-			if err != nil {
-				break
-			}
-			if next == '[' {
-				var csiSequence bytes.Buffer
-				for {
-					seqRune, _, seqErr := reader.ReadRune()
-					if seqErr != nil {
-						break
-					}
-					csiSequence.WriteRune(seqRune)
-					if seqRune >= 0x40 && seqRune <= 0x7E {
-						break
-					}
-				}
-				fmt.Fprintln(w, Event{Type: EventANSI, Payload: csiSequence.String()})
-			} else {
-				fmt.Fprintln(w, Event{Type: EventUnhandled, Payload: string(next)})
-			}
-		case '\r', '\n':
-			if textBuffer.Len() > 0 {
-				fmt.Fprintln(w, Event{Type: EventText, Payload: textBuffer.String()})
-				textBuffer.Reset()
-			}
-			fmt.Fprintln(w, Event{Type: EventControl, Payload: string(r)})
-		default:
-			textBuffer.WriteRune(r)
-		}
 	}
+	flush()
 }
@@ -10,7 +10,11 @@ import (
 
 var (
 	parseRunOptsFunc   = ParseRunOpts
-	runInteractiveFunc = ptyx.RunInteractive
+	runInteractiveFunc = runInteractive
+	newConsoleFunc     = ptyx.NewConsole
+	newTTYConsoleFunc  = ptyx.NewTTYConsole
+	runConsoleFunc     = ptyx.RunConsole
+	runPipedFunc       = ptyx.RunInteractive
 )
 
 func main() {
@@ -25,3 +29,18 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// runInteractive attaches the program to the terminal. When stdout is
+// redirected it uses the controlling terminal instead, the way less and fzf
+// do, and only falls back to plain pipes when there is no terminal at all.
+func runInteractive(ctx context.Context, opts ptyx.SpawnOpts) error {
+	c, err := newConsoleFunc()
+	if ptyx.IsErrNotAConsole(err) {
+		c, err = newTTYConsoleFunc()
+	}
+	if err != nil {
+		return runPipedFunc(ctx, opts)
+	}
+	defer c.Close()
+	return runConsoleFunc(ctx, c, opts)
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/KennethanCeyer/ptyx"
+	"github.com/KennethanCeyer/ptyx/testptyx"
 )
 
 
@@ -165,3 +166,57 @@ func TestRun_MainExecution(t *testing.T) {
 		}
 	})
 }
+
+func TestRunInteractive_ConsoleSelection(t *testing.T) {
+	tty := testptyx.NewMockConsole("")
+	stdout := testptyx.NewMockConsole("")
+	tests := []struct {
+		name       string
+		consoleErr error
+		ttyErr     error
+		want       string
+		wantCon    ptyx.Console
+	}{
+		{"Stdout", nil, nil, "console", stdout},
+		{"RedirectedStdout", ptyx.ErrNotAConsole, nil, "console", tty},
+		{"NoTerminal", ptyx.ErrNotAConsole, ptyx.ErrNotAConsole, "piped", nil},
+		{"ConsoleError", errors.New("boom"), nil, "piped", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origConsole, origTTY, origRun, origPiped := newConsoleFunc, newTTYConsoleFunc, runConsoleFunc, runPipedFunc
+			t.Cleanup(func() {
+				newConsoleFunc, newTTYConsoleFunc, runConsoleFunc, runPipedFunc = origConsole, origTTY, origRun, origPiped
+			})
+			newConsoleFunc = func() (ptyx.Console, error) {
+				if tt.consoleErr != nil {
+					return nil, tt.consoleErr
+				}
+				return stdout, nil
+			}
+			newTTYConsoleFunc = func() (ptyx.Console, error) {
+				if tt.ttyErr != nil {
+					return nil, tt.ttyErr
+				}
+				return tty, nil
+			}
+			var got string
+			var gotCon ptyx.Console
+			runConsoleFunc = func(ctx context.Context, c ptyx.Console, opts ptyx.SpawnOpts) error {
+				got, gotCon = "console", c
+				return nil
+			}
+			runPipedFunc = func(ctx context.Context, opts ptyx.SpawnOpts) error {
+				got = "piped"
+				return nil
+			}
+
+			if err := runInteractive(context.Background(), ptyx.SpawnOpts{Prog: "sh"}); err != nil {
+				t.Fatalf("runInteractive() = %v", err)
+			}
+			if got != tt.want || gotCon != tt.wantCon {
+				t.Errorf("ran %s on %v, want %s on %v", got, gotCon, tt.want, tt.wantCon)
+			}
+		})
+	}
+}
@@ -62,6 +62,9 @@ func (m *mockSequenceSession) PtyWriter() io.Writer {
 	return m.ptyIn
 }
 func (m *mockSequenceSession) Resize(cols, rows int) error { return nil }
+func (m *mockSequenceSession) ResizeWithPixels(cols, rows, xpixel, ypixel int) error { return nil }
+func (m *mockSequenceSession) SendKeys(keys ...any) error                           { return nil }
+func (m *mockSequenceSession) KeyModes() ptyx.KeyModes                              { return ptyx.KeyModes{} }
 func (m *mockSequenceSession) Wait() error {
 	if m.eofChan != nil {
 		<-m.eofChan
@@ -72,6 +75,17 @@ func (m *mockSequenceSession) Kill() error                 { return nil }
 func (m *mockSequenceSession) Close() error                { return nil }
 func (m *mockSequenceSession) Pid() int                    { return 1234 }
 func (m *mockSequenceSession) CloseStdin() error           { return nil }
+func (m *mockSequenceSession) SendEOF() error              { return nil }
+func (m *mockSequenceSession) StdinPipe() io.WriteCloser { return nil }
+func (m *mockSequenceSession) StdoutPipe() io.ReadCloser { return nil }
+func (m *mockSequenceSession) StderrPipe() io.ReadCloser { return nil }
+func (m *mockSequenceSession) Signal(sig os.Signal) error           { return nil }
+func (m *mockSequenceSession) SignalForeground(sig os.Signal) error { return nil }
+func (m *mockSequenceSession) Termios() (ptyx.Termios, error) { return ptyx.DefaultTermios, nil }
+func (m *mockSequenceSession) SetTermios(t ptyx.Termios) error  { return nil }
+func (m *mockSequenceSession) Terminate(ctx context.Context, p ptyx.TerminatePolicy) (ptyx.TerminateStep, error) {
+	return ptyx.TerminateNone, nil
+}
 
 func TestSequenceHelperProcess(t *testing.T) {
 	if os.Getenv("GO_TEST_SEQUENCE") == "1" {
@@ -2,6 +2,7 @@ package ptyx
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"sync"
@@ -24,11 +25,23 @@ type console struct {
 	outTTY, errTTY bool
 	raw            RawState
 	win            *resizeWatcher
+	owned          []*os.File
 	closeOnce      sync.Once
+	queryMu        sync.Mutex
+	pendingDA1     int
+	modesMu        sync.Mutex
+	modes          []terminalMode
+	closeErr       error
 }
 
 func NewConsole() (Console, error) {
-	c := &console{in: os.Stdin, out: os.Stdout, err: os.Stderr}
+	return NewConsoleFrom(os.Stdin, os.Stdout, os.Stderr)
+}
+
+// NewConsoleFrom returns a Console on the given files. out must be a
+// terminal; the files are not closed by Close.
+func NewConsoleFrom(in, out, err *os.File) (Console, error) {
+	c := &console{in: in, out: out, err: err}
 	if c.out == nil {
 		return nil, ErrNotAConsole
 	}
@@ -41,6 +54,29 @@ func NewConsole() (Console, error) {
 	return c, nil
 }
 
+// NewTTYConsole returns a Console on the controlling terminal, opened
+// directly, so a program can stay interactive while its standard streams are
+// redirected. Close closes the terminal files.
+func NewTTYConsole() (Console, error) {
+	in, out, err := openTTY()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAConsole, err)
+	}
+	owned := []*os.File{in}
+	if out != in {
+		owned = append(owned, out)
+	}
+	c, cerr := NewConsoleFrom(in, out, out)
+	if cerr != nil {
+		for _, f := range owned {
+			_ = f.Close()
+		}
+		return nil, cerr
+	}
+	c.(*console).owned = owned
+	return c, nil
+}
+
 func (c *console) In() io.Reader {
 	if c.in == nil {
 		return nil
@@ -76,8 +112,14 @@ func (c *console) Close() error {
 		if c.win != nil && c.win.stop != nil {
 			close(c.win.stop)
 		}
+		c.resetModes()
+		for _, f := range c.owned {
+			if err := f.Close(); err != nil && c.closeErr == nil {
+				c.closeErr = err
+			}
+		}
 	})
-	return nil
+	return c.closeErr
 }
 
 func (c *console) IsATTYOut() bool {
@@ -116,7 +158,14 @@ func (c *console) MakeRaw() (RawState, error) {
 	return r, nil
 }
 
+// Restore leaves raw mode and turns off the input modes enabled through the
+// console, such as the kitty keyboard protocol.
 func (c *console) Restore(s RawState) error {
+	c.resetModes()
+	return c.restoreRaw(s)
+}
+
+func (c *console) restoreRaw(s RawState) error {
 	r, ok := s.(*rawState)
 	if !ok || r == nil || r.st == nil {
 		return nil
@@ -127,3 +176,80 @@ func (c *console) Restore(s RawState) error {
 	}
 	return err
 }
+
+// terminalMode is a terminal input mode turned on through the console, with
+// the sequence that turns it off again.
+type terminalMode struct {
+	name    string
+	disable string
+}
+
+// setMode writes enable and records disable under name, first turning off
+// the mode if it is already on.
+func (c *console) setMode(name, enable, disable string) error {
+	if c.out == nil {
+		return ErrNotAConsole
+	}
+	c.modesMu.Lock()
+	defer c.modesMu.Unlock()
+	seq := enable
+	for i, m := range c.modes {
+		if m.name == name {
+			seq = m.disable + enable
+			c.modes = append(c.modes[:i], c.modes[i+1:]...)
+			break
+		}
+	}
+	if _, err := c.out.WriteString(seq); err != nil {
+		return err
+	}
+	c.modes = append(c.modes, terminalMode{name, disable})
+	return nil
+}
+
+func (c *console) clearMode(name string) error {
+	c.modesMu.Lock()
+	defer c.modesMu.Unlock()
+	for i, m := range c.modes {
+		if m.name == name {
+			c.modes = append(c.modes[:i], c.modes[i+1:]...)
+			_, err := c.out.WriteString(m.disable)
+			return err
+		}
+	}
+	return nil
+}
+
+// resetModes turns off every mode still on, most recent first.
+func (c *console) resetModes() {
+	c.modesMu.Lock()
+	defer c.modesMu.Unlock()
+	for i := len(c.modes) - 1; i >= 0; i-- {
+		_, _ = c.out.WriteString(c.modes[i].disable)
+	}
+	c.modes = nil
+}
+
+// EnableKittyKeyboard pushes flags onto the terminal's kitty keyboard
+// protocol stack. Terminals without the protocol ignore it.
+func (c *console) EnableKittyKeyboard(flags KittyFlags) error {
+	return c.setMode("kitty", KittyKeyboardPush(flags), KittyKeyboardPop(1))
+}
+
+func (c *console) DisableKittyKeyboard() error { return c.clearMode("kitty") }
+
+// EnableBracketedPaste makes the terminal mark pasted text, which an
+// InputReader reports as a PasteEvent.
+func (c *console) EnableBracketedPaste() error {
+	return c.setMode("paste", DECSET(ModeBracketedPaste), DECRST(ModeBracketedPaste))
+}
+
+func (c *console) DisableBracketedPaste() error { return c.clearMode("paste") }
+
+// EnableFocusEvents makes the terminal report focus changes, which an
+// InputReader reports as FocusEvents.
+func (c *console) EnableFocusEvents() error {
+	return c.setMode("focus", DECSET(ModeFocus), DECRST(ModeFocus))
+}
+
+func (c *console) DisableFocusEvents() error { return c.clearMode("focus") }
@@ -6,11 +6,58 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 func (c *console) EnableVT() {
 }
 
+// openTTY opens /dev/tty once for both reading and writing.
+func openTTY() (in, out *os.File, err error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f, nil
+}
+
+// readTimeout reads from the console input once it is readable, or returns
+// ErrQueryTimeout after d.
+func (c *console) readTimeout(p []byte, d time.Duration) (int, error) {
+	fd := int(c.in.Fd())
+	deadline := time.Now().Add(d)
+	for {
+		ms := int(time.Until(deadline).Milliseconds())
+		if ms <= 0 {
+			return 0, ErrQueryTimeout
+		}
+		n, err := unix.Poll([]unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}, ms)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, ErrQueryTimeout
+		}
+		return unix.Read(fd, p)
+	}
+}
+
+func (c *console) SizeInfo() Size {
+	if c.out == nil {
+		return Size{}
+	}
+	ws, err := unix.IoctlGetWinsize(int(c.out.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return Size{}
+	}
+	return Size{Cols: int(ws.Col), Rows: int(ws.Row), XPixel: int(ws.Xpixel), YPixel: int(ws.Ypixel)}
+}
+
 func (c *console) initResizeWatcher() {
 	c.win = &resizeWatcher{C: make(chan struct{}, 1), stop: make(chan struct{}), ready: make(chan struct{})}
 	go func() {
@@ -3,6 +3,8 @@
 package ptyx
 
 import (
+	"context"
+	"errors"
 	"os"
 	"syscall"
 	"testing"
@@ -52,3 +54,181 @@ func TestUnixConsole_ResizeSignal(t *testing.T) {
 		t.Fatal("OnResize() did not receive a signal within 2s")
 	}
 }
+
+func TestUnixConsole_SizeInfo(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Fatalf("failed to open pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+	if err := setWinsizePixels(int(master.Fd()), 120, 40, 1200, 800); err != nil {
+		t.Fatalf("failed to set pty size: %v", err)
+	}
+
+	c := &console{in: slave, out: slave, err: slave, outTTY: true, errTTY: true}
+	want := Size{Cols: 120, Rows: 40, XPixel: 1200, YPixel: 800}
+	if got := c.SizeInfo(); got != want {
+		t.Errorf("SizeInfo() = %+v, want %+v", got, want)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	notTTY := &console{in: r, out: w, err: w}
+	if got := notTTY.SizeInfo(); got != (Size{}) {
+		t.Errorf("SizeInfo() on a non-terminal = %+v, want zero", got)
+	}
+}
+
+func TestNewConsoleFrom(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Fatalf("failed to open pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+	if err := setWinsize(int(master.Fd()), 100, 30); err != nil {
+		t.Fatalf("failed to set pty size: %v", err)
+	}
+
+	c, err := NewConsoleFrom(slave, slave, slave)
+	if err != nil {
+		t.Fatalf("NewConsoleFrom() failed: %v", err)
+	}
+	if w, h := c.Size(); w != 100 || h != 30 {
+		t.Errorf("Size() = %d, %d, want 100, 30", w, h)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if _, err := slave.Stat(); err != nil {
+		t.Errorf("Close() closed the caller's file: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if _, err := NewConsoleFrom(slave, w, w); !errors.Is(err, ErrNotAConsole) {
+		t.Errorf("NewConsoleFrom() with a pipe = %v, want ErrNotAConsole", err)
+	}
+}
+
+func TestNewTTYConsole(t *testing.T) {
+	line, err := spawnReadOneLineAndClose(context.Background(), SpawnOpts{
+		Prog: os.Args[0],
+		Args: []string{"-test.run=^TestHelperProcess$"},
+		Env:  append(os.Environ(), "PTYX_HELPER=1", "MODE=ttyconsole"),
+		Cols: 100,
+		Rows: 30,
+	}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	if line != "tty 100x30" {
+		t.Errorf("helper output = %q, want %q", line, "tty 100x30")
+	}
+}
+
+func TestConsole_InputModesReset(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Fatalf("failed to open pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	readMaster := func() string {
+		t.Helper()
+		buf := make([]byte, 256)
+		n, err := master.Read(buf)
+		if err != nil {
+			t.Fatalf("reading master failed: %v", err)
+		}
+		return string(buf[:n])
+	}
+
+	c := &console{in: slave, out: slave, err: slave, outTTY: true}
+	if err := c.EnableKittyKeyboard(KittyDisambiguate); err != nil {
+		t.Fatalf("EnableKittyKeyboard() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[>1u" {
+		t.Errorf("enable wrote %q", got)
+	}
+	if err := c.EnableKittyKeyboard(KittyDisambiguate | KittyReportAllKeys); err != nil {
+		t.Fatalf("EnableKittyKeyboard() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[<1u\x1b[>9u" {
+		t.Errorf("re-enable wrote %q, want a pop before the new push", got)
+	}
+
+	st, err := c.MakeRaw()
+	if err != nil {
+		t.Fatalf("MakeRaw() failed: %v", err)
+	}
+	if err := c.Restore(st); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[<1u" {
+		t.Errorf("Restore() wrote %q, want the kitty flags popped", got)
+	}
+
+	if err := c.EnableMouse(MouseAnyMotion, MouseEncodingSGR); err != nil {
+		t.Fatalf("EnableMouse() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[?1003;1006h" {
+		t.Errorf("EnableMouse() wrote %q", got)
+	}
+	if err := c.DisableMouse(); err != nil {
+		t.Fatalf("DisableMouse() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[?1003;1006l" {
+		t.Errorf("DisableMouse() wrote %q", got)
+	}
+	if err := c.EnableMouse(MouseTracking(-1), MouseEncodingSGR); err == nil {
+		t.Error("EnableMouse() with invalid tracking should fail")
+	}
+
+	if err := c.EnableBracketedPaste(); err != nil {
+		t.Fatalf("EnableBracketedPaste() failed: %v", err)
+	}
+	if err := c.EnableFocusEvents(); err != nil {
+		t.Fatalf("EnableFocusEvents() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[?2004h\x1b[?1004h" {
+		t.Errorf("enabling paste and focus wrote %q", got)
+	}
+	if err := c.DisableFocusEvents(); err != nil {
+		t.Fatalf("DisableFocusEvents() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[?1004l" {
+		t.Errorf("DisableFocusEvents() wrote %q", got)
+	}
+
+	if err := c.EnableKittyKeyboard(KittyDisambiguate); err != nil {
+		t.Fatalf("EnableKittyKeyboard() failed: %v", err)
+	}
+	readMaster()
+	if err := c.EnableMouse(MouseButtonMotion, MouseEncodingSGR); err != nil {
+		t.Fatalf("EnableMouse() failed: %v", err)
+	}
+	readMaster()
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if got := readMaster(); got != "\x1b[?1002;1006l\x1b[<1u\x1b[?2004l" {
+		t.Errorf("Close() wrote %q, want every enabled mode turned off", got)
+	}
+	if err := c.DisableBracketedPaste(); err != nil {
+		t.Errorf("DisableBracketedPaste() after Close() = %v", err)
+	}
+	if err := c.DisableKittyKeyboard(); err != nil {
+		t.Errorf("DisableKittyKeyboard() after Close() = %v", err)
+	}
+}
@@ -3,6 +3,7 @@
 package ptyx
 
 import (
+	"os"
 	"time"
 
 	"golang.org/x/sys/windows"
@@ -24,6 +25,40 @@ func (c *console) EnableVT() {
 	}
 }
 
+// openTTY opens the console input and screen buffers, which stay attached
+// to the console when the standard handles are redirected.
+func openTTY() (in, out *os.File, err error) {
+	in, err = os.OpenFile("CONIN$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err = os.OpenFile("CONOUT$", os.O_RDWR, 0)
+	if err != nil {
+		_ = in.Close()
+		return nil, nil, err
+	}
+	return in, out, nil
+}
+
+// readTimeout reads from the console input once it is signaled, or returns
+// ErrQueryTimeout after d.
+func (c *console) readTimeout(p []byte, d time.Duration) (int, error) {
+	ev, err := windows.WaitForSingleObject(windows.Handle(c.in.Fd()), uint32(d.Milliseconds()))
+	if err != nil {
+		return 0, err
+	}
+	if ev == uint32(windows.WAIT_TIMEOUT) {
+		return 0, ErrQueryTimeout
+	}
+	return c.in.Read(p)
+}
+
+// SizeInfo has no pixel size to report on Windows.
+func (c *console) SizeInfo() Size {
+	w, h := c.Size()
+	return Size{Cols: w, Rows: h}
+}
+
 func (c *console) initResizeWatcher() {
 	c.win = &resizeWatcher{C: make(chan struct{}, 1), stop: make(chan struct{}), ready: make(chan struct{})}
 	go func() {
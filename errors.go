@@ -3,19 +3,68 @@ package ptyx
 import (
 	"errors"
 	"fmt"
+	"os"
 )
 
 var ErrMuxAlreadyStarted = errors.New("ptyx: mux already started")
 
+var ErrUnsupported = errors.New("ptyx: operation not supported on this platform")
+
 type ExitError struct {
 	ExitCode int
+	// Exited is true if the process exited on its own rather than being
+	// killed by a signal (or terminated, on Windows).
+	Exited bool
+	// Signal is the signal that killed the process, if any. On Windows it is
+	// os.Kill when the process was terminated by Kill or its context.
+	Signal     os.Signal
+	CoreDumped bool
+	// Canceled is true if the process was stopped because its context was
+	// done. Unwrap returns the context's error in that case. A process that
+	// exits with status 0 as it is stopped is not an error at all.
+	Canceled bool
+
+	ctxErr     error
 	waitStatus any
 }
 
 func (e *ExitError) Error() string {
-	return fmt.Sprintf("process exited with status %d", e.ExitCode)
+	var msg string
+	if e.Signal != nil && !e.Exited {
+		msg = fmt.Sprintf("process killed by signal %v", e.Signal)
+		if e.CoreDumped {
+			msg += " (core dumped)"
+		}
+	} else {
+		msg = fmt.Sprintf("process exited with status %d", e.ExitCode)
+	}
+	if e.Canceled && e.ctxErr != nil {
+		msg += ": " + e.ctxErr.Error()
+	}
+	return msg
 }
 
 func (e *ExitError) Sys() any {
 	return e.waitStatus
 }
+
+// Success reports whether the process exited with status 0.
+func (e *ExitError) Success() bool { return e.Exited && e.ExitCode == 0 }
+
+func (e *ExitError) Unwrap() error { return e.ctxErr }
+
+// canceledError marks err, the result of Wait after the process was stopped
+// because ctx was done, as caused by the cancellation. A nil err means the
+// process exited with status 0 before it could be stopped, and stays nil.
+func canceledError(err, ctxErr error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		e := *exitErr
+		e.Canceled, e.ctxErr = true, ctxErr
+		return &e
+	}
+	return &ExitError{ExitCode: -1, Canceled: true, ctxErr: ctxErr}
+}
@@ -1,24 +1,68 @@
 package ptyx
 
 import (
+	"context"
+	"errors"
+	"os"
 	"testing"
 )
 
 func TestExitError_Error(t *testing.T) {
 	tests := []struct {
-		name     string
-		exitCode int
-		want     string
+		name string
+		err  *ExitError
+		want string
 	}{
-		{"Non-zero exit code", 127, "process exited with status 127"},
-		{"Zero exit code", 0, "process exited with status 0"},
+		{"Non-zero exit code", &ExitError{ExitCode: 127}, "process exited with status 127"},
+		{"Zero exit code", &ExitError{ExitCode: 0}, "process exited with status 0"},
+		{"Signaled", &ExitError{ExitCode: -1, Signal: os.Kill}, "process killed by signal killed"},
+		{"CoreDumped", &ExitError{ExitCode: -1, Signal: os.Kill, CoreDumped: true}, "process killed by signal killed (core dumped)"},
+		{"Canceled", &ExitError{ExitCode: -1, Signal: os.Kill, Canceled: true, ctxErr: context.Canceled}, "process killed by signal killed: context canceled"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			e := &ExitError{ExitCode: tt.exitCode}
-			if got := e.Error(); got != tt.want {
+			if got := tt.err.Error(); got != tt.want {
 				t.Errorf("ExitError.Error() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestExitError_Success(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ExitError
+		want bool
+	}{
+		{"ExitedZero", &ExitError{Exited: true}, true},
+		{"ExitedNonZero", &ExitError{Exited: true, ExitCode: 1}, false},
+		{"Signaled", &ExitError{ExitCode: -1, Signal: os.Kill}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Success(); got != tt.want {
+				t.Errorf("Success() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanceledError(t *testing.T) {
+	orig := &ExitError{ExitCode: -1, Signal: os.Kill}
+	err := canceledError(orig, context.DeadlineExceeded)
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || !exitErr.Canceled || exitErr.Signal != os.Kill {
+		t.Fatalf("canceledError() = %#v, want a canceled copy of the ExitError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("canceledError() should wrap the context error")
+	}
+	if orig.Canceled {
+		t.Error("canceledError() modified its argument")
+	}
+
+	if err := canceledError(nil, context.Canceled); err != nil {
+		t.Errorf("canceledError(nil) = %#v, want nil for a clean exit", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package expect drives a ptyx.Session by waiting for patterns in its output
+// and sending input in response.
+package expect
+
+import (
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx"
+)
+
+// DefaultMaxBuffer is the match window used when Options.MaxBuffer is not
+// set.
+const DefaultMaxBuffer = 64 * 1024
+
+var (
+	// ErrTimeout is returned when nothing matched before the timeout.
+	ErrTimeout = errors.New("expect: timeout")
+	// ErrEOF is returned when the output ended without a match.
+	ErrEOF = errors.New("expect: EOF")
+	// ErrNoPatterns is returned when Expect is called without patterns.
+	ErrNoPatterns = errors.New("expect: no patterns")
+)
+
+// Options configures an Expecter.
+type Options struct {
+	// MaxBuffer bounds the window of unmatched output kept for matching.
+	// The oldest bytes are discarded once it is exceeded.
+	MaxBuffer int
+	// Tee, if set, receives a copy of everything read from the session.
+	Tee io.Writer
+}
+
+// Match describes what an Expect call matched. Index is the position of the
+// matching pattern in the call's arguments, Groups holds its submatches and
+// Before is the output skipped over to reach it.
+type Match struct {
+	Index  int
+	Text   string
+	Groups []string
+	Before string
+}
+
+// Expecter reads a session's output in the background and matches it
+// against patterns. Output is consumed up to the end of each match.
+type Expecter struct {
+	s   ptyx.Session
+	max int
+	tee io.Writer
+
+	mu     sync.Mutex
+	buf    []byte
+	err    error
+	notify chan struct{}
+}
+
+// New starts reading s's PtyReader, which must not be read elsewhere.
+func New(s ptyx.Session, opts Options) *Expecter {
+	e := &Expecter{
+		s:      s,
+		max:    opts.MaxBuffer,
+		tee:    opts.Tee,
+		notify: make(chan struct{}),
+	}
+	if e.max <= 0 {
+		e.max = DefaultMaxBuffer
+	}
+	go e.readLoop(s.PtyReader())
+	return e
+}
+
+func (e *Expecter) readLoop(r io.Reader) {
+	p := make([]byte, 4096)
+	for {
+		n, err := r.Read(p)
+		if n > 0 {
+			if e.tee != nil {
+				_, _ = e.tee.Write(p[:n])
+			}
+			e.mu.Lock()
+			e.buf = append(e.buf, p[:n]...)
+			if over := len(e.buf) - e.max; over > 0 {
+				e.buf = append(e.buf[:0], e.buf[over:]...)
+			}
+			e.signal()
+			e.mu.Unlock()
+		}
+		if err != nil {
+			e.mu.Lock()
+			e.err = err
+			e.signal()
+			e.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (e *Expecter) signal() {
+	close(e.notify)
+	e.notify = make(chan struct{})
+}
+
+// Expect waits for the first of patterns, matched literally, to appear in
+// the output. When several match, the one starting earliest wins. A timeout
+// of zero or less waits without limit.
+func (e *Expecter) Expect(timeout time.Duration, patterns ...string) (*Match, error) {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = regexp.MustCompile(regexp.QuoteMeta(p))
+	}
+	return e.ExpectRegexp(timeout, res...)
+}
+
+// ExpectRegexp is Expect with regular expressions.
+func (e *Expecter) ExpectRegexp(timeout time.Duration, patterns ...*regexp.Regexp) (*Match, error) {
+	if len(patterns) == 0 {
+		return nil, ErrNoPatterns
+	}
+	var m *Match
+	err := e.wait(timeout, func() bool {
+		m = e.match(patterns)
+		return m != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ExpectEOF waits for the output to end and returns what was left unmatched.
+// Read errors other than the end of output are returned. A timeout of zero
+// or less waits without limit.
+func (e *Expecter) ExpectEOF(timeout time.Duration) (string, error) {
+	var out string
+	err := e.wait(timeout, func() bool {
+		if e.err == nil || !isEOF(e.err) {
+			return false
+		}
+		out = string(e.buf)
+		e.buf = e.buf[:0]
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// Send writes s to the session as is.
+func (e *Expecter) Send(s string) error {
+	_, err := io.WriteString(e.s.PtyWriter(), s)
+	return err
+}
+
+// SendLine sends s followed by a carriage return, like pressing Enter.
+func (e *Expecter) SendLine(s string) error { return e.Send(s + "\r") }
+
+// Buffered returns the output read but not yet consumed by a match.
+func (e *Expecter) Buffered() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return string(e.buf)
+}
+
+func (e *Expecter) wait(timeout time.Duration, done func() bool) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		deadline = t.C
+	}
+	for {
+		e.mu.Lock()
+		if done() {
+			e.mu.Unlock()
+			return nil
+		}
+		if e.err != nil {
+			err := e.err
+			e.mu.Unlock()
+			if isEOF(err) {
+				return ErrEOF
+			}
+			return err
+		}
+		ch := e.notify
+		e.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-deadline:
+			return ErrTimeout
+		}
+	}
+}
+
+func (e *Expecter) match(patterns []*regexp.Regexp) *Match {
+	best, bestIdx := -1, []int(nil)
+	for i, re := range patterns {
+		loc := re.FindSubmatchIndex(e.buf)
+		if loc == nil {
+			continue
+		}
+		if bestIdx == nil || loc[0] < bestIdx[0] {
+			best, bestIdx = i, loc
+		}
+	}
+	if bestIdx == nil {
+		return nil
+	}
+	m := &Match{
+		Index:  best,
+		Text:   string(e.buf[bestIdx[0]:bestIdx[1]]),
+		Before: string(e.buf[:bestIdx[0]]),
+	}
+	for g := 2; g < len(bestIdx); g += 2 {
+		if bestIdx[g] < 0 {
+			m.Groups = append(m.Groups, "")
+			continue
+		}
+		m.Groups = append(m.Groups, string(e.buf[bestIdx[g]:bestIdx[g+1]]))
+	}
+	e.buf = append(e.buf[:0], e.buf[bestIdx[1]:]...)
+	return m
+}
+
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) || errors.Is(err, syscall.EIO)
+}
@@ -0,0 +1,187 @@
+package expect
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx/testptyx"
+)
+
+type chunkReader struct {
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := copy(p, r.chunks[0])
+	r.chunks[0] = r.chunks[0][n:]
+	if r.chunks[0] == "" {
+		r.chunks = r.chunks[1:]
+	}
+	return n, nil
+}
+
+func newPipeExpecter(opts Options) (*Expecter, *testptyx.MockSession, *io.PipeWriter) {
+	pr, pw := io.Pipe()
+	s := testptyx.NewMockSession("")
+	s.PtyOutReader = pr
+	return New(s, opts), s, pw
+}
+
+func TestExpect(t *testing.T) {
+	t.Run("LiteralSplitAcrossReads", func(t *testing.T) {
+		s := testptyx.NewMockSession("")
+		s.PtyOutReader = &chunkReader{chunks: []string{"login: ro", "ot\r\nPass", "word: "}}
+		e := New(s, Options{})
+
+		m, err := e.Expect(time.Second, "Password: ")
+		if err != nil {
+			t.Fatalf("Expect() failed: %v", err)
+		}
+		if m.Text != "Password: " || m.Before != "login: root\r\n" || m.Index != 0 {
+			t.Errorf("Expect() = %+v", m)
+		}
+	})
+
+	t.Run("EarliestPatternWins", func(t *testing.T) {
+		s := testptyx.NewMockSession("")
+		s.PtyOutReader = &chunkReader{chunks: []string{"error: boom\n$ "}}
+		e := New(s, Options{})
+
+		m, err := e.Expect(time.Second, "$ ", "error:")
+		if err != nil {
+			t.Fatalf("Expect() failed: %v", err)
+		}
+		if m.Index != 1 || m.Before != "" {
+			t.Errorf("Expect() = %+v, want index 1 with empty Before", m)
+		}
+		m, err = e.Expect(time.Second, "$ ")
+		if err != nil {
+			t.Fatalf("second Expect() failed: %v", err)
+		}
+		if m.Before != " boom\n" {
+			t.Errorf("second Expect().Before = %q, want %q", m.Before, " boom\n")
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		e, _, pw := newPipeExpecter(Options{})
+		defer pw.Close()
+		_, _ = pw.Write([]byte("nothing here"))
+
+		_, err := e.Expect(50*time.Millisecond, "prompt")
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Expect() error = %v, want ErrTimeout", err)
+		}
+		if got := e.Buffered(); got != "nothing here" {
+			t.Errorf("Buffered() = %q after timeout", got)
+		}
+	})
+
+	t.Run("EOF", func(t *testing.T) {
+		s := testptyx.NewMockSession("partial")
+		e := New(s, Options{})
+
+		_, err := e.Expect(time.Second, "prompt")
+		if !errors.Is(err, ErrEOF) {
+			t.Fatalf("Expect() error = %v, want ErrEOF", err)
+		}
+	})
+
+	t.Run("NoPatterns", func(t *testing.T) {
+		e := New(testptyx.NewMockSession(""), Options{})
+		if _, err := e.Expect(time.Second); !errors.Is(err, ErrNoPatterns) {
+			t.Fatalf("Expect() error = %v, want ErrNoPatterns", err)
+		}
+	})
+}
+
+func TestExpectRegexp(t *testing.T) {
+	s := testptyx.NewMockSession("ready pid=4242 port=8080\n")
+	e := New(s, Options{})
+
+	m, err := e.ExpectRegexp(time.Second, regexp.MustCompile(`pid=(\d+) (?:port=(\d+))?(x)?`))
+	if err != nil {
+		t.Fatalf("ExpectRegexp() failed: %v", err)
+	}
+	want := []string{"4242", "8080", ""}
+	if strings.Join(m.Groups, ",") != strings.Join(want, ",") {
+		t.Errorf("Groups = %q, want %q", m.Groups, want)
+	}
+	if m.Before != "ready " {
+		t.Errorf("Before = %q, want %q", m.Before, "ready ")
+	}
+}
+
+func TestExpectEOF(t *testing.T) {
+	s := testptyx.NewMockSession("")
+	s.PtyOutReader = &chunkReader{chunks: []string{"one\n", "two\n"}}
+	e := New(s, Options{})
+
+	out, err := e.ExpectEOF(time.Second)
+	if err != nil {
+		t.Fatalf("ExpectEOF() failed: %v", err)
+	}
+	if out != "one\ntwo\n" {
+		t.Errorf("ExpectEOF() = %q", out)
+	}
+
+	e2, _, pw := newPipeExpecter(Options{})
+	defer pw.Close()
+	if _, err := e2.ExpectEOF(20 * time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Errorf("ExpectEOF() on open stream error = %v, want ErrTimeout", err)
+	}
+
+	readErr := errors.New("read failed")
+	e3, _, pw3 := newPipeExpecter(Options{})
+	_, _ = pw3.Write([]byte("partial"))
+	pw3.CloseWithError(readErr)
+	if out, err := e3.ExpectEOF(time.Second); !errors.Is(err, readErr) {
+		t.Errorf("ExpectEOF() after a read error = %q, %v; want %v", out, err, readErr)
+	}
+}
+
+func TestExpecter_BoundedWindow(t *testing.T) {
+	s := testptyx.NewMockSession(strings.Repeat("x", 100) + "tail")
+	e := New(s, Options{MaxBuffer: 16})
+
+	m, err := e.Expect(time.Second, "tail")
+	if err != nil {
+		t.Fatalf("Expect() failed: %v", err)
+	}
+	if len(m.Before) != 12 {
+		t.Errorf("len(Before) = %d, want 12", len(m.Before))
+	}
+}
+
+func TestExpecter_SendAndTee(t *testing.T) {
+	var tee bytes.Buffer
+	s := testptyx.NewMockSession("echo")
+	e := New(s, Options{Tee: &tee})
+
+	if err := e.Send("ab"); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if err := e.SendLine("cd"); err != nil {
+		t.Fatalf("SendLine() failed: %v", err)
+	}
+	if got := s.PtyInBuffer.String(); got != "abcd\r" {
+		t.Errorf("written = %q, want %q", got, "abcd\r")
+	}
+
+	if _, err := e.ExpectEOF(time.Second); err != nil {
+		t.Fatalf("ExpectEOF() failed: %v", err)
+	}
+	if tee.String() != "echo" {
+		t.Errorf("tee = %q, want %q", tee.String(), "echo")
+	}
+}
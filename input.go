@@ -0,0 +1,397 @@
+package ptyx
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultEscTimeout is how long an InputReader waits after an ESC for the
+// rest of an escape sequence.
+const DefaultEscTimeout = 50 * time.Millisecond
+
+// InputEvent is an event decoded from terminal input, such as a KeyEvent.
+type InputEvent interface{ inputEvent() }
+
+func (KeyEvent) inputEvent() {}
+
+// PasteEvent is text pasted while bracketed paste is enabled. Text is passed
+// on as the terminal sent it, so line breaks are usually "\r".
+type PasteEvent struct {
+	Text string
+}
+
+func (PasteEvent) inputEvent() {}
+
+// FocusEvent reports that the terminal gained (In) or lost focus while focus
+// reporting is enabled.
+type FocusEvent struct {
+	In bool
+}
+
+func (FocusEvent) inputEvent() {}
+
+var (
+	pasteStart = []byte("\x1b[200~")
+	pasteEnd   = []byte("\x1b[201~")
+)
+
+// InputReader decodes raw terminal input, typically Console.In() in raw mode,
+// into events.
+type InputReader struct {
+	// EscTimeout is how long an ESC waits for the rest of a sequence before
+	// it is taken as the Escape key, or as Alt with the keys that follow.
+	EscTimeout time.Duration
+
+	data chan inputChunk
+	buf  []byte
+	err  error
+}
+
+type inputChunk struct {
+	b   []byte
+	err error
+}
+
+// NewInputReader starts reading r in the background. The reader is read until
+// it returns an error, so r should be closed when events are no longer
+// wanted.
+func NewInputReader(r io.Reader) *InputReader {
+	ir := &InputReader{EscTimeout: DefaultEscTimeout, data: make(chan inputChunk)}
+	go func() {
+		for {
+			b := make([]byte, 1024)
+			n, err := r.Read(b)
+			if n > 0 || err != nil {
+				ir.data <- inputChunk{b[:n], err}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ir
+}
+
+// ReadEvent returns the next event. Once the input is exhausted it returns
+// the reader's error, io.EOF for a plain end of input.
+func (ir *InputReader) ReadEvent() (InputEvent, error) {
+	for {
+		if len(ir.buf) > 0 {
+			ev, n := decodeEvent(ir.buf, ir.err != nil)
+			if n == 0 && bytes.HasPrefix(ir.buf, pasteStart) {
+				// A paste can take longer than EscTimeout to arrive.
+				ir.add(<-ir.data)
+			} else if n == 0 {
+				t := time.NewTimer(ir.EscTimeout)
+				select {
+				case c := <-ir.data:
+					ir.add(c)
+				case <-t.C:
+					ev, n = decodeEvent(ir.buf, true)
+				}
+				t.Stop()
+			}
+			ir.buf = ir.buf[n:]
+			if ev != nil {
+				return ev, nil
+			}
+			continue
+		}
+		if ir.err != nil {
+			return nil, ir.err
+		}
+		ir.add(<-ir.data)
+	}
+}
+
+func (ir *InputReader) add(c inputChunk) {
+	ir.buf = append(ir.buf, c.b...)
+	if c.err != nil {
+		ir.err = c.err
+	}
+}
+
+// decodeEvent decodes the event at the start of b and the number of bytes it
+// used. Unless final is set, n is 0 when b ends in the middle of a sequence
+// that more input could complete; with final set at least one byte is always
+// used. ev is nil for sequences that are consumed but not reported.
+func decodeEvent(b []byte, final bool) (ev InputEvent, n int) {
+	if b[0] != 0x1b {
+		return decodeChar(b, final)
+	}
+	if len(b) == 1 {
+		if !final {
+			return nil, 0
+		}
+		return KeyEvent{Key: KeyEscape}, 1
+	}
+	switch b[1] {
+	case '[':
+		seq, ok := scanCSI(b[2:])
+		if ok && string(seq) == "M" {
+			// X10 mouse encoding: three bytes follow, each offset by 32.
+			if len(b) < 6 {
+				if !final {
+					return nil, 0
+				}
+				return nil, len(b)
+			}
+			return decodeMouse(int(b[3])-32, int(b[4])-32, int(b[5])-32, false), 6
+		}
+		if ok && string(seq) == "200~" {
+			text := b[len(pasteStart):]
+			if i := bytes.Index(text, pasteEnd); i >= 0 {
+				return PasteEvent{Text: string(text[:i])}, len(pasteStart) + i + len(pasteEnd)
+			}
+			if !final {
+				return nil, 0
+			}
+			return PasteEvent{Text: string(text)}, len(b)
+		}
+		if ok {
+			return decodeCSI(seq), 2 + len(seq)
+		}
+		if len(seq) == len(b)-2 && !final {
+			return nil, 0
+		}
+	case 'O':
+		if len(b) > 2 {
+			if k, ok := decodeSS3(b[2]); ok {
+				return k, 3
+			}
+		} else if !final {
+			return nil, 0
+		}
+	}
+	ev, n = decodeEvent(b[1:], final)
+	if n == 0 {
+		return nil, 0
+	}
+	if k, ok := ev.(KeyEvent); ok {
+		k.Mods |= ModAlt
+		return k, n + 1
+	}
+	return KeyEvent{Key: KeyEscape}, 1
+}
+
+func decodeChar(b []byte, final bool) (InputEvent, int) {
+	c := b[0]
+	switch {
+	case c == '\r':
+		return KeyEvent{Key: KeyEnter}, 1
+	case c == '\t':
+		return KeyEvent{Key: KeyTab}, 1
+	case c == 0x7f:
+		return KeyEvent{Key: KeyBackspace}, 1
+	case c == 0:
+		return KeyEvent{Rune: ' ', Mods: ModCtrl}, 1
+	case c < 0x1b:
+		return KeyEvent{Rune: rune('a' + c - 1), Mods: ModCtrl}, 1
+	case c < 0x20:
+		return KeyEvent{Rune: rune('\\' + c - 0x1c), Mods: ModCtrl}, 1
+	}
+	if !final && !utf8.FullRune(b) {
+		return nil, 0
+	}
+	r, n := utf8.DecodeRune(b)
+	return KeyEvent{Rune: r}, n
+}
+
+// scanCSI returns the parameter, intermediate and final bytes of the CSI
+// sequence at the start of b. ok is false if b ends before the final byte or
+// holds a byte that cannot appear in a CSI sequence.
+func scanCSI(b []byte) (seq []byte, ok bool) {
+	for i, c := range b {
+		switch {
+		case c >= 0x20 && c <= 0x3f:
+		case c >= 0x40 && c <= 0x7e:
+			return b[:i+1], true
+		default:
+			return b[:i], false
+		}
+	}
+	return b, false
+}
+
+type csiSeq struct {
+	private       byte
+	params        [][]int
+	intermediates string
+	final         byte
+}
+
+// param returns sub-parameter j of parameter i, or def if it is missing.
+func (s csiSeq) param(i, j, def int) int {
+	if i < len(s.params) && j < len(s.params[i]) && s.params[i][j] >= 0 {
+		return s.params[i][j]
+	}
+	return def
+}
+
+func parseCSI(seq []byte) csiSeq {
+	s := csiSeq{final: seq[len(seq)-1]}
+	body := string(seq[:len(seq)-1])
+	if body != "" && body[0] >= '<' && body[0] <= '?' {
+		s.private, body = body[0], body[1:]
+	}
+	if i := strings.IndexFunc(body, func(r rune) bool { return r < '0' || r > ';' }); i >= 0 {
+		s.intermediates, body = body[i:], body[:i]
+	}
+	if body == "" {
+		return s
+	}
+	for _, p := range strings.Split(body, ";") {
+		var sub []int
+		for _, v := range strings.Split(p, ":") {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				n = -1
+			}
+			sub = append(sub, n)
+		}
+		s.params = append(s.params, sub)
+	}
+	return s
+}
+
+var csiLetterKeys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd, 'E': KeyBegin,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+var csiTildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPageUp, 6: KeyPageDown, 7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5,
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10, 23: KeyF11, 24: KeyF12,
+	25: KeyF13, 26: KeyF14, 28: KeyF15, 29: KeyF16, 31: KeyF17, 32: KeyF18, 33: KeyF19, 34: KeyF20,
+}
+
+func decodeCSI(seq []byte) InputEvent {
+	s := parseCSI(seq)
+	if s.final == 'M' || s.final == 'm' {
+		return decodeCSIMouse(s)
+	}
+	if (s.final == 'I' || s.final == 'O') && s.private == 0 && s.params == nil && s.intermediates == "" {
+		return FocusEvent{In: s.final == 'I'}
+	}
+	if s.private != 0 || s.intermediates != "" {
+		return nil
+	}
+	var k KeyEvent
+	mods := Mod(max(s.param(1, 0, 1), 1) - 1)
+	switch s.final {
+	case 'u':
+		var ok bool
+		if k, ok = kittyKey(s.param(0, 0, 0)); !ok {
+			return nil
+		}
+		if shifted := s.param(0, 1, 0); shifted > 0 && k.Key == KeyRune && mods&ModShift != 0 {
+			k.Rune = rune(shifted)
+		}
+	case '~':
+		code := s.param(0, 0, 0)
+		if code == 27 {
+			// xterm modifyOtherKeys: CSI 27 ; mods ; code ~
+			var ok bool
+			if k, ok = kittyKey(s.param(2, 0, 0)); !ok {
+				return nil
+			}
+			break
+		}
+		key, ok := csiTildeKeys[code]
+		if !ok {
+			return nil
+		}
+		k.Key = key
+	case 'Z':
+		k.Key, mods = KeyTab, mods|ModShift
+	default:
+		key, ok := csiLetterKeys[s.final]
+		if !ok {
+			return nil
+		}
+		k.Key = key
+	}
+	k.Mods = mods
+	switch s.param(1, 1, 1) {
+	case 2:
+		k.Repeat = true
+	case 3:
+		k.Release = true
+	}
+	return k
+}
+
+// decodeCSIMouse decodes SGR (CSI < b ; x ; y M or m) and URXVT
+// (CSI b ; x ; y M) mouse reports.
+func decodeCSIMouse(s csiSeq) InputEvent {
+	if len(s.params) != 3 || s.intermediates != "" {
+		return nil
+	}
+	cb, x, y := s.param(0, 0, 0), s.param(1, 0, 1), s.param(2, 0, 1)
+	switch {
+	case s.private == '<':
+		return decodeMouse(cb, x, y, s.final == 'm')
+	case s.private == 0 && s.final == 'M':
+		return decodeMouse(cb-32, x, y, false)
+	}
+	return nil
+}
+
+// kittyKey maps a kitty keyboard protocol key code, which is a Unicode code
+// point for keys that have one, to a key.
+func kittyKey(code int) (KeyEvent, bool) {
+	switch {
+	case code == 9:
+		return KeyEvent{Key: KeyTab}, true
+	case code == 13:
+		return KeyEvent{Key: KeyEnter}, true
+	case code == 27:
+		return KeyEvent{Key: KeyEscape}, true
+	case code == 127 || code == 8:
+		return KeyEvent{Key: KeyBackspace}, true
+	case code >= 57376 && code <= 57383:
+		return KeyEvent{Key: KeyF13 + Key(code-57376)}, true
+	case code >= 57399 && code <= 57408:
+		return KeyEvent{Rune: rune('0' + code - 57399), Keypad: true}, true
+	}
+	if k, ok := kittyKeypad[code]; ok {
+		k.Keypad = true
+		return k, true
+	}
+	if code < ' ' || code > utf8.MaxRune || code >= 57344 && code <= 63743 {
+		// The remaining private-use codes are modifier and media keys.
+		return KeyEvent{}, false
+	}
+	return KeyEvent{Rune: rune(code)}, true
+}
+
+var kittyKeypad = map[int]KeyEvent{
+	57409: {Rune: '.'}, 57410: {Rune: '/'}, 57411: {Rune: '*'}, 57412: {Rune: '-'},
+	57413: {Rune: '+'}, 57414: {Key: KeyEnter}, 57415: {Rune: '='}, 57416: {Rune: ','},
+	57417: {Key: KeyLeft}, 57418: {Key: KeyRight}, 57419: {Key: KeyUp}, 57420: {Key: KeyDown},
+	57421: {Key: KeyPageUp}, 57422: {Key: KeyPageDown}, 57423: {Key: KeyHome}, 57424: {Key: KeyEnd},
+	57425: {Key: KeyInsert}, 57426: {Key: KeyDelete}, 57427: {Key: KeyBegin},
+}
+
+// decodeSS3 decodes ESC O sequences, sent for F1-F4 and, in application
+// cursor and keypad modes, for the cursor and keypad keys.
+func decodeSS3(c byte) (KeyEvent, bool) {
+	if k, ok := csiLetterKeys[c]; ok {
+		return KeyEvent{Key: k}, true
+	}
+	switch {
+	case c == 'M':
+		return KeyEvent{Key: KeyEnter, Keypad: true}, true
+	case c == 'X':
+		return KeyEvent{Rune: '=', Keypad: true}, true
+	case c >= 'j' && c <= 'y':
+		return KeyEvent{Rune: rune(c - 'j' + '*'), Keypad: true}, true
+	}
+	return KeyEvent{}, false
+}
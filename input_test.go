@@ -0,0 +1,151 @@
+package ptyx
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// chunkReader returns one chunk per Read, pausing before each.
+type chunkReader struct {
+	chunks []string
+	pause  time.Duration
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.pause)
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func readEvents(t *testing.T, ir *InputReader) []InputEvent {
+	t.Helper()
+	var evs []InputEvent
+	for {
+		ev, err := ir.ReadEvent()
+		if errors.Is(err, io.EOF) {
+			return evs
+		}
+		if err != nil {
+			t.Fatalf("ReadEvent() = %v", err)
+		}
+		evs = append(evs, ev)
+	}
+}
+
+func key(k Key, m Mod) KeyEvent   { return KeyEvent{Key: k, Mods: m} }
+func char(r rune, m Mod) KeyEvent { return KeyEvent{Rune: r, Mods: m} }
+
+func TestInputReader_Keys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []InputEvent
+	}{
+		{"Text", "hé", []InputEvent{char('h', 0), char('é', 0)}},
+		{"Controls", "\r\t\x7f\x03\x00\x1f\n", []InputEvent{
+			key(KeyEnter, 0), key(KeyTab, 0), key(KeyBackspace, 0), char('c', ModCtrl),
+			char(' ', ModCtrl), char('_', ModCtrl), char('j', ModCtrl),
+		}},
+		{"Arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []InputEvent{key(KeyUp, 0), key(KeyDown, 0), key(KeyRight, 0), key(KeyLeft, 0)}},
+		{"AppCursor", "\x1bOA\x1bOH\x1bOP", []InputEvent{key(KeyUp, 0), key(KeyHome, 0), key(KeyF1, 0)}},
+		{"Keypad", "\x1bOM\x1bOp\x1bOk", []InputEvent{
+			KeyEvent{Key: KeyEnter, Keypad: true}, KeyEvent{Rune: '0', Keypad: true}, KeyEvent{Rune: '+', Keypad: true},
+		}},
+		{"Tilde", "\x1b[2~\x1b[3~\x1b[5~\x1b[6~\x1b[1~\x1b[4~", []InputEvent{
+			key(KeyInsert, 0), key(KeyDelete, 0), key(KeyPageUp, 0), key(KeyPageDown, 0), key(KeyHome, 0), key(KeyEnd, 0),
+		}},
+		{"FunctionKeys", "\x1b[15~\x1b[24~\x1b[34~", []InputEvent{key(KeyF5, 0), key(KeyF12, 0), key(KeyF20, 0)}},
+		{"XtermModifiers", "\x1b[1;5A\x1b[1;2H\x1b[3;3~\x1b[1;6P\x1b[Z", []InputEvent{
+			key(KeyUp, ModCtrl), key(KeyHome, ModShift), key(KeyDelete, ModAlt), key(KeyF1, ModCtrl|ModShift), key(KeyTab, ModShift),
+		}},
+		{"ModifyOtherKeys", "\x1b[27;5;13~", []InputEvent{key(KeyEnter, ModCtrl)}},
+		{"Alt", "\x1bx\x1b\x1b[A\x1b\x7f", []InputEvent{char('x', ModAlt), key(KeyUp, ModAlt), key(KeyBackspace, ModAlt)}},
+		{"AltCtrl", "\x1b\x01", []InputEvent{char('a', ModCtrl|ModAlt)}},
+		{"Kitty", "\x1b[97u\x1b[97;5u\x1b[97:65;2u\x1b[13u\x1b[27u\x1b[57399u\x1b[57414;5u", []InputEvent{
+			char('a', 0), char('a', ModCtrl), char('A', ModShift), key(KeyEnter, 0), key(KeyEscape, 0),
+			KeyEvent{Rune: '0', Keypad: true}, KeyEvent{Key: KeyEnter, Mods: ModCtrl, Keypad: true},
+		}},
+		{"KittyEvents", "\x1b[97;1:2u\x1b[1;5:3A", []InputEvent{
+			KeyEvent{Rune: 'a', Repeat: true}, KeyEvent{Key: KeyUp, Mods: ModCtrl, Release: true},
+		}},
+		{"KittyModifierKeyDropped", "\x1b[57441;2ux", []InputEvent{char('x', 0)}},
+		{"UnknownCSIDropped", "\x1b[?1u\x1b[>1;2cx", []InputEvent{char('x', 0)}},
+		{"TrailingEscape", "a\x1b", []InputEvent{char('a', 0), key(KeyEscape, 0)}},
+		{"TruncatedCSI", "\x1b[1;", []InputEvent{char('[', ModAlt), char('1', 0), char(';', 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readEvents(t, NewInputReader(&chunkReader{chunks: []string{tt.in}}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputReader_SplitSequence(t *testing.T) {
+	ir := NewInputReader(&chunkReader{chunks: []string{"\x1b", "[", "1;5", "A", "\xe4\xb8", "\x96"}, pause: 5 * time.Millisecond})
+	ir.EscTimeout = time.Second
+	got := readEvents(t, ir)
+	if want := []InputEvent{key(KeyUp, ModCtrl), char('世', 0)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestInputReader_EscTimeout(t *testing.T) {
+	ir := NewInputReader(&chunkReader{chunks: []string{"\x1b", "[A"}, pause: 100 * time.Millisecond})
+	ir.EscTimeout = 20 * time.Millisecond
+	got := readEvents(t, ir)
+	if want := []InputEvent{key(KeyEscape, 0), char('[', 0), char('A', 0)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestInputReader_Error(t *testing.T) {
+	r, w := io.Pipe()
+	ir := NewInputReader(r)
+	go func() {
+		_, _ = w.Write([]byte("q"))
+		w.CloseWithError(errors.New("boom"))
+	}()
+	if ev, err := ir.ReadEvent(); err != nil || ev != char('q', 0) {
+		t.Fatalf("ReadEvent() = %v, %v", ev, err)
+	}
+	if _, err := ir.ReadEvent(); err == nil || err.Error() != "boom" {
+		t.Errorf("ReadEvent() error = %v, want boom", err)
+	}
+}
+
+func TestInputReader_PasteAndFocus(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []InputEvent
+	}{
+		{"Paste", []string{"a\x1b[200~hi\x1b[A\x03\rthere\x1b[201~b"}, []InputEvent{
+			char('a', 0), PasteEvent{Text: "hi\x1b[A\x03\rthere"}, char('b', 0),
+		}},
+		{"EmptyPaste", []string{"\x1b[200~\x1b[201~"}, []InputEvent{PasteEvent{}}},
+		{"SplitPaste", []string{"\x1b[200~abc", "def\x1b[2", "01~"}, []InputEvent{PasteEvent{Text: "abcdef"}}},
+		{"UnterminatedPaste", []string{"\x1b[200~abc"}, []InputEvent{PasteEvent{Text: "abc"}}},
+		{"Focus", []string{"\x1b[I\x1b[O"}, []InputEvent{FocusEvent{In: true}, FocusEvent{In: false}}},
+		{"FocusIsNotSS3", []string{"\x1b[Ox\x1bOA"}, []InputEvent{FocusEvent{}, char('x', 0), key(KeyUp, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := NewInputReader(&chunkReader{chunks: tt.chunks, pause: 30 * time.Millisecond})
+			ir.EscTimeout = 10 * time.Millisecond
+			got := readEvents(t, ir)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package ptyx
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/KennethanCeyer/ptyx/vtparse"
+)
+
+// KeyModes are the modes a program sets that change what the terminal sends
+// for its keys.
+type KeyModes struct {
+	// AppCursor is DECCKM: unmodified cursor keys send ESC O instead of CSI.
+	AppCursor bool
+	// AppKeypad is DECKPAM: keypad keys send ESC O sequences.
+	AppKeypad bool
+	// BracketedPaste wraps text in paste markers.
+	BracketedPaste bool
+}
+
+// Common control keys, for SendKeys.
+var (
+	KeyCtrlC = Ctrl('c')
+	KeyCtrlD = Ctrl('d')
+	KeyCtrlL = Ctrl('l')
+	KeyCtrlZ = Ctrl('z')
+)
+
+// Ctrl returns the key event for r with Ctrl held.
+func Ctrl(r rune) KeyEvent { return KeyEvent{Rune: r, Mods: ModCtrl} }
+
+// With returns the key event for k with mods held.
+func (k Key) With(mods Mod) KeyEvent { return KeyEvent{Key: k, Mods: mods} }
+
+var keyLetters = map[Key]byte{
+	KeyUp: 'A', KeyDown: 'B', KeyRight: 'C', KeyLeft: 'D',
+	KeyHome: 'H', KeyEnd: 'F', KeyBegin: 'E',
+}
+
+var keyTildes = map[Key]int{
+	KeyInsert: 2, KeyDelete: 3, KeyPageUp: 5, KeyPageDown: 6,
+	KeyF5: 15, KeyF6: 17, KeyF7: 18, KeyF8: 19, KeyF9: 20, KeyF10: 21, KeyF11: 23, KeyF12: 24,
+	KeyF13: 25, KeyF14: 26, KeyF15: 28, KeyF16: 29, KeyF17: 31, KeyF18: 32, KeyF19: 33, KeyF20: 34,
+}
+
+// EncodeKey returns what xterm sends for e in modes m. Modified keys use
+// xterm's modifier parameter, and modified characters without a control
+// code of their own use its modifyOtherKeys form. Releases send nothing.
+func EncodeKey(e KeyEvent, m KeyModes) []byte {
+	if e.Release {
+		return nil
+	}
+	mods := e.Mods & (ModShift | ModAlt | ModCtrl | ModSuper)
+	switch e.Key {
+	case KeyRune:
+		return encodeRune(e.Rune, mods, e.Keypad && m.AppKeypad)
+	case KeyEnter:
+		if e.Keypad && m.AppKeypad && mods == 0 {
+			return []byte("\x1bOM")
+		}
+		return encodeC0('\r', mods)
+	case KeyTab:
+		if mods == ModShift {
+			return []byte("\x1b[Z")
+		}
+		return encodeC0('\t', mods)
+	case KeyBackspace:
+		if mods&^ModAlt == ModCtrl {
+			return encodeC0('\b', mods&ModAlt)
+		}
+		return encodeC0(0x7f, mods)
+	case KeyEscape:
+		return encodeC0(0x1b, mods)
+	}
+	if c, ok := keyLetters[e.Key]; ok {
+		if mods == 0 && m.AppCursor {
+			return []byte{0x1b, 'O', c}
+		}
+		return encodeLetter(c, mods)
+	}
+	if e.Key >= KeyF1 && e.Key <= KeyF4 {
+		c := byte('P' + e.Key - KeyF1)
+		if mods == 0 {
+			return []byte{0x1b, 'O', c}
+		}
+		return encodeLetter(c, mods)
+	}
+	if n, ok := keyTildes[e.Key]; ok {
+		s := strconv.Itoa(n)
+		if mods != 0 {
+			s += ";" + strconv.Itoa(int(mods)+1)
+		}
+		return []byte(CSI(s + "~"))
+	}
+	return nil
+}
+
+func encodeLetter(c byte, mods Mod) []byte {
+	if mods == 0 {
+		return []byte{0x1b, '[', c}
+	}
+	return []byte(CSI("1;" + strconv.Itoa(int(mods)+1) + string(c)))
+}
+
+// encodeC0 encodes a key that sends a single control code, with Alt as an
+// ESC prefix.
+func encodeC0(c byte, mods Mod) []byte {
+	switch mods {
+	case 0:
+		return []byte{c}
+	case ModAlt:
+		return []byte{0x1b, c}
+	}
+	return modifyOtherKeys(rune(c), mods)
+}
+
+func encodeRune(r rune, mods Mod, appKeypad bool) []byte {
+	if appKeypad && mods == 0 {
+		switch {
+		case r == '=':
+			return []byte("\x1bOX")
+		case r >= '*' && r <= '9':
+			return []byte{0x1b, 'O', byte(r-'*') + 'j'}
+		}
+	}
+	if mods&ModShift != 0 && unicode.IsLower(r) {
+		r = unicode.ToUpper(r)
+	}
+	rest := mods &^ (ModShift | ModAlt)
+	var b []byte
+	switch rest {
+	case 0:
+		b = utf8.AppendRune(nil, r)
+	case ModCtrl:
+		c, ok := ctrlCode(r)
+		if !ok {
+			return modifyOtherKeys(r, mods)
+		}
+		b = []byte{c}
+	default:
+		return modifyOtherKeys(r, mods)
+	}
+	if mods&ModAlt != 0 {
+		b = append([]byte{0x1b}, b...)
+	}
+	return b
+}
+
+// ctrlCode returns the control code sent for Ctrl and r.
+func ctrlCode(r rune) (byte, bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return byte(r - 'a' + 1), true
+	case r >= '@' && r <= '_':
+		return byte(r - '@'), true
+	case r == ' ':
+		return 0, true
+	case r == '?':
+		return 0x7f, true
+	}
+	return 0, false
+}
+
+func modifyOtherKeys(r rune, mods Mod) []byte {
+	return []byte(CSI("27;" + strconv.Itoa(int(mods)+1) + ";" + strconv.Itoa(int(r)) + "~"))
+}
+
+// encodeText encodes s the way a terminal pastes it: newlines become
+// carriage returns, like Enter, and in bracketed paste mode the text is
+// wrapped in markers, with any end marker inside it removed.
+func encodeText(s string, m KeyModes) []byte {
+	s = strings.ReplaceAll(s, "\r\n", "\r")
+	s = strings.ReplaceAll(s, "\n", "\r")
+	if !m.BracketedPaste {
+		return []byte(s)
+	}
+	s = strings.ReplaceAll(s, string(pasteEnd), "")
+	return append(append(append([]byte(nil), pasteStart...), s...), pasteEnd...)
+}
+
+// EncodeKeys encodes keys for SendKeys. Each key is a Key, a KeyEvent, a
+// rune, or a string of text.
+func EncodeKeys(m KeyModes, keys ...any) ([]byte, error) {
+	var b []byte
+	for _, k := range keys {
+		switch k := k.(type) {
+		case Key:
+			b = append(b, EncodeKey(KeyEvent{Key: k}, m)...)
+		case KeyEvent:
+			b = append(b, EncodeKey(k, m)...)
+		case rune:
+			b = append(b, EncodeKey(KeyEvent{Rune: k}, m)...)
+		case string:
+			b = append(b, encodeText(k, m)...)
+		default:
+			return nil, fmt.Errorf("ptyx: cannot send %T as keys", k)
+		}
+	}
+	return b, nil
+}
+
+// keyModeTracker follows the KeyModes a program sets in the output written
+// to it.
+type keyModeTracker struct {
+	mu    sync.Mutex
+	p     vtparse.Parser
+	modes KeyModes
+}
+
+func (t *keyModeTracker) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.p.Parse(b, func(e vtparse.Event) {
+		switch {
+		case e.Kind == vtparse.KindESC && len(e.Intermediates) == 0:
+			switch e.Final {
+			case '=':
+				t.modes.AppKeypad = true
+			case '>':
+				t.modes.AppKeypad = false
+			case 'c':
+				t.modes = KeyModes{}
+			}
+		case e.Kind == vtparse.KindCSI && e.Private == '?' && len(e.Intermediates) == 0 && (e.Final == 'h' || e.Final == 'l'):
+			for i := 0; i < e.Params.Len(); i++ {
+				switch e.Params.Get(i, 0) {
+				case ModeCursorKeys:
+					t.modes.AppCursor = e.Final == 'h'
+				case ModeBracketedPaste:
+					t.modes.BracketedPaste = e.Final == 'h'
+				}
+			}
+		case e.Kind == vtparse.KindCSI && e.Private == 0 && string(e.Intermediates) == "!" && e.Final == 'p':
+			t.modes.AppCursor, t.modes.AppKeypad = false, false
+		}
+	})
+	return len(b), nil
+}
+
+func (t *keyModeTracker) get() KeyModes {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.modes
+}
+
+// sendKeys encodes keys for the modes t has seen and writes them to w in
+// one write.
+func (t *keyModeTracker) sendKeys(w io.Writer, keys []any) error {
+	b, err := EncodeKeys(t.get(), keys...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,167 @@
+package ptyx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeKey(t *testing.T) {
+	app := KeyModes{AppCursor: true, AppKeypad: true}
+	tests := []struct {
+		name  string
+		ev    KeyEvent
+		modes KeyModes
+		want  string
+	}{
+		{"Rune", KeyEvent{Rune: 'a'}, KeyModes{}, "a"},
+		{"UTF8", KeyEvent{Rune: 'é'}, KeyModes{}, "é"},
+		{"ShiftRune", KeyEvent{Rune: 'a', Mods: ModShift}, KeyModes{}, "A"},
+		{"CtrlC", KeyCtrlC, KeyModes{}, "\x03"},
+		{"CtrlSpace", Ctrl(' '), KeyModes{}, "\x00"},
+		{"CtrlBracket", Ctrl('['), KeyModes{}, "\x1b"},
+		{"AltRune", KeyEvent{Rune: 'x', Mods: ModAlt}, KeyModes{}, "\x1bx"},
+		{"CtrlAlt", KeyEvent{Rune: 'a', Mods: ModCtrl | ModAlt}, KeyModes{}, "\x1b\x01"},
+		{"CtrlDigit", Ctrl('1'), KeyModes{}, "\x1b[27;5;49~"},
+		{"SuperRune", KeyEvent{Rune: 'a', Mods: ModSuper}, KeyModes{}, "\x1b[27;9;97~"},
+		{"Enter", KeyEvent{Key: KeyEnter}, KeyModes{}, "\r"},
+		{"CtrlEnter", KeyEnter.With(ModCtrl), KeyModes{}, "\x1b[27;5;13~"},
+		{"Tab", KeyEvent{Key: KeyTab}, KeyModes{}, "\t"},
+		{"ShiftTab", KeyTab.With(ModShift), KeyModes{}, "\x1b[Z"},
+		{"Backspace", KeyEvent{Key: KeyBackspace}, KeyModes{}, "\x7f"},
+		{"CtrlBackspace", KeyBackspace.With(ModCtrl), KeyModes{}, "\b"},
+		{"AltBackspace", KeyBackspace.With(ModAlt), KeyModes{}, "\x1b\x7f"},
+		{"Escape", KeyEvent{Key: KeyEscape}, KeyModes{}, "\x1b"},
+		{"Up", KeyEvent{Key: KeyUp}, KeyModes{}, "\x1b[A"},
+		{"UpAppCursor", KeyEvent{Key: KeyUp}, app, "\x1bOA"},
+		{"CtrlUpAppCursor", KeyUp.With(ModCtrl), app, "\x1b[1;5A"},
+		{"ShiftHome", KeyHome.With(ModShift), KeyModes{}, "\x1b[1;2H"},
+		{"F1", KeyEvent{Key: KeyF1}, KeyModes{}, "\x1bOP"},
+		{"CtrlShiftF1", KeyF1.With(ModCtrl | ModShift), KeyModes{}, "\x1b[1;6P"},
+		{"F5", KeyEvent{Key: KeyF5}, KeyModes{}, "\x1b[15~"},
+		{"F20", KeyEvent{Key: KeyF20}, KeyModes{}, "\x1b[34~"},
+		{"AltDelete", KeyDelete.With(ModAlt), KeyModes{}, "\x1b[3;3~"},
+		{"PageDown", KeyEvent{Key: KeyPageDown}, KeyModes{}, "\x1b[6~"},
+		{"KeypadDigit", KeyEvent{Rune: '5', Keypad: true}, KeyModes{}, "5"},
+		{"KeypadDigitApp", KeyEvent{Rune: '5', Keypad: true}, app, "\x1bOu"},
+		{"KeypadPlusApp", KeyEvent{Rune: '+', Keypad: true}, app, "\x1bOk"},
+		{"KeypadEqualsApp", KeyEvent{Rune: '=', Keypad: true}, app, "\x1bOX"},
+		{"KeypadEnterApp", KeyEvent{Key: KeyEnter, Keypad: true}, app, "\x1bOM"},
+		{"LockModsIgnored", KeyEvent{Rune: 'a', Mods: ModNumLock | ModCapsLock}, KeyModes{}, "a"},
+		{"Release", KeyEvent{Rune: 'a', Release: true}, KeyModes{}, ""},
+		{"Unknown", KeyEvent{Key: Key(99)}, KeyModes{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(EncodeKey(tt.ev, tt.modes)); got != tt.want {
+				t.Errorf("EncodeKey(%+v) = %q, want %q", tt.ev, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeKey_RoundTrip(t *testing.T) {
+	events := []KeyEvent{
+		{Rune: 'a'}, {Rune: 'a', Mods: ModCtrl}, {Rune: 'x', Mods: ModAlt}, {Rune: 'a', Mods: ModCtrl | ModAlt},
+		{Key: KeyEnter}, {Key: KeyEnter, Mods: ModCtrl}, {Key: KeyTab, Mods: ModShift}, {Key: KeyBackspace, Mods: ModAlt},
+		{Key: KeyUp}, {Key: KeyUp, Mods: ModCtrl}, {Key: KeyHome, Mods: ModShift}, {Key: KeyDelete, Mods: ModAlt},
+		{Key: KeyF1, Mods: ModCtrl | ModShift}, {Key: KeyF5}, {Key: KeyF12}, {Key: KeyF20}, {Key: KeyPageUp},
+		{Rune: '7', Keypad: true}, {Key: KeyEnter, Keypad: true},
+	}
+	for _, m := range []KeyModes{{}, {AppCursor: true, AppKeypad: true}} {
+		var in []byte
+		var want []InputEvent
+		for _, ev := range events {
+			in = append(in, EncodeKey(ev, m)...)
+			if !m.AppKeypad {
+				ev.Keypad = false
+			}
+			want = append(want, ev)
+		}
+		got := readEvents(t, NewInputReader(bytes.NewReader(in)))
+		if len(got) != len(want) {
+			t.Fatalf("modes %+v: decoded %d events %v, want %v", m, len(got), got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("modes %+v: event %d = %#v, want %#v", m, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestEncodeKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		modes KeyModes
+		keys  []any
+		want  string
+	}{
+		{"Mixed", KeyModes{}, []any{KeyUp, KeyCtrlC, "text", KeyF5, 'q'}, "\x1b[A\x03text\x1b[15~q"},
+		{"AppCursor", KeyModes{AppCursor: true}, []any{KeyDown}, "\x1bOB"},
+		{"Newlines", KeyModes{}, []any{"a\nb\r\nc"}, "a\rb\rc"},
+		{"BracketedPaste", KeyModes{BracketedPaste: true}, []any{"ls\n", KeyEnter}, "\x1b[200~ls\r\x1b[201~\r"},
+		{"PasteEndStripped", KeyModes{BracketedPaste: true}, []any{"a\x1b[201~b"}, "\x1b[200~ab\x1b[201~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeKeys(tt.modes, tt.keys...)
+			if err != nil {
+				t.Fatalf("EncodeKeys() failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeKeys(KeyModes{}, KeyUp, 3.5); err == nil {
+		t.Error("EncodeKeys() with a float should fail")
+	}
+}
+
+func TestKeyModeTracker(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+		want   KeyModes
+	}{
+		{"None", []string{"hello\r\n"}, KeyModes{}},
+		{"Set", []string{"\x1b[?1h\x1b=\x1b[?2004h"}, KeyModes{AppCursor: true, AppKeypad: true, BracketedPaste: true}},
+		{"Combined", []string{"\x1b[?1;2004h"}, KeyModes{AppCursor: true, BracketedPaste: true}},
+		{"Split", []string{"\x1b[?", "1", "h\x1b", "="}, KeyModes{AppCursor: true, AppKeypad: true}},
+		{"Reset", []string{"\x1b[?1;2004h\x1b=", "\x1b[?1l\x1b>\x1b[?2004l"}, KeyModes{}},
+		{"SoftReset", []string{"\x1b[?1;2004h\x1b=\x1b[!p"}, KeyModes{BracketedPaste: true}},
+		{"FullReset", []string{"\x1b[?1;2004h\x1b=\x1bc"}, KeyModes{}},
+		{"OtherModes", []string{"\x1b[?25l\x1b[1h\x1b[?1049h"}, KeyModes{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tr keyModeTracker
+			for _, o := range tt.output {
+				_, _ = tr.Write([]byte(o))
+			}
+			if got := tr.get(); got != tt.want {
+				t.Errorf("modes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyModeTracker_SendKeys(t *testing.T) {
+	var tr keyModeTracker
+	_, _ = tr.Write([]byte("\x1b[?1h\x1b[?2004h"))
+	var buf bytes.Buffer
+	if err := tr.sendKeys(&buf, []any{KeyLeft, "hi"}); err != nil {
+		t.Fatalf("sendKeys() failed: %v", err)
+	}
+	if got, want := buf.String(), "\x1bOD\x1b[200~hi\x1b[201~"; got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+	buf.Reset()
+	if err := tr.sendKeys(&buf, []any{KeyLeft, nil}); err == nil {
+		t.Error("sendKeys() with nil should fail")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("sent %q after an encoding error, want nothing", buf.String())
+	}
+}
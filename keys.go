@@ -0,0 +1,168 @@
+package ptyx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Key identifies a key that does not produce a character. Characters,
+// including Ctrl and Alt combinations of letters, are KeyRune with the
+// character in KeyEvent.Rune.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyBegin
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyF13
+	KeyF14
+	KeyF15
+	KeyF16
+	KeyF17
+	KeyF18
+	KeyF19
+	KeyF20
+)
+
+var keyNames = map[Key]string{
+	KeyRune:      "rune",
+	KeyEnter:     "enter",
+	KeyTab:       "tab",
+	KeyBackspace: "backspace",
+	KeyEscape:    "esc",
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyRight:     "right",
+	KeyLeft:      "left",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+	KeyBegin:     "begin",
+	KeyInsert:    "insert",
+	KeyDelete:    "delete",
+	KeyPageUp:    "pgup",
+	KeyPageDown:  "pgdown",
+}
+
+func (k Key) String() string {
+	if k >= KeyF1 && k <= KeyF20 {
+		return "f" + strconv.Itoa(int(k-KeyF1)+1)
+	}
+	if s, ok := keyNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Key(%d)", int(k))
+}
+
+// Mod is a set of modifier keys. The bits match the kitty keyboard protocol
+// and, for Shift, Alt, Ctrl and Super (Meta in xterm), xterm's modifier
+// parameter minus one.
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+	ModSuper
+	ModHyper
+	ModMeta
+	ModCapsLock
+	ModNumLock
+)
+
+var modNames = []struct {
+	m    Mod
+	name string
+}{
+	{ModCtrl, "ctrl"}, {ModAlt, "alt"}, {ModShift, "shift"}, {ModSuper, "super"},
+	{ModHyper, "hyper"}, {ModMeta, "meta"}, {ModCapsLock, "capslock"}, {ModNumLock, "numlock"},
+}
+
+func (m Mod) String() string {
+	var parts []string
+	for _, n := range modNames {
+		if m&n.m != 0 {
+			parts = append(parts, n.name)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// KeyEvent is a key press decoded from terminal input. Repeat and Release
+// are only reported by the kitty keyboard protocol with KittyReportEvents.
+// Keypad is set for keys known to come from the numeric keypad, which is
+// only distinguishable in application keypad mode or with kitty.
+//
+// The kitty protocol also reports Caps Lock and Num Lock in Mods, so mask
+// them out before comparing events:
+//
+//	ev.Mods &^= ModCapsLock | ModNumLock
+type KeyEvent struct {
+	Key     Key
+	Rune    rune
+	Mods    Mod
+	Repeat  bool
+	Release bool
+	Keypad  bool
+}
+
+// String names the key with its modifiers, for example "ctrl+c",
+// "alt+shift+up" or "space".
+func (e KeyEvent) String() string {
+	name := e.Key.String()
+	if e.Key == KeyRune {
+		switch e.Rune {
+		case ' ':
+			name = "space"
+		default:
+			name = string(e.Rune)
+		}
+	}
+	if mods := (e.Mods &^ (ModCapsLock | ModNumLock)).String(); mods != "" {
+		return mods + "+" + name
+	}
+	return name
+}
+
+// KittyFlags selects the kitty keyboard protocol enhancements to enable.
+type KittyFlags int
+
+const (
+	KittyDisambiguate KittyFlags = 1 << iota
+	KittyReportEvents
+	KittyReportAlternates
+	KittyReportAllKeys
+	KittyReportText
+)
+
+// KittyKeyboardPush enables the kitty keyboard protocol with flags, saving
+// the previous flags on the terminal's stack.
+func KittyKeyboardPush(flags KittyFlags) string { return CSI(">" + strconv.Itoa(int(flags)) + "u") }
+
+// KittyKeyboardPop restores the flags saved by the last n pushes.
+func KittyKeyboardPop(n int) string { return CSI("<" + strconv.Itoa(n) + "u") }
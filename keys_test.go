@@ -0,0 +1,41 @@
+package ptyx
+
+import "testing"
+
+func TestKeyEvent_String(t *testing.T) {
+	tests := []struct {
+		ev   KeyEvent
+		want string
+	}{
+		{KeyEvent{Rune: 'a'}, "a"},
+		{KeyEvent{Rune: ' '}, "space"},
+		{KeyEvent{Rune: 'c', Mods: ModCtrl}, "ctrl+c"},
+		{KeyEvent{Key: KeyUp, Mods: ModShift | ModAlt}, "alt+shift+up"},
+		{KeyEvent{Key: KeyF12}, "f12"},
+		{KeyEvent{Key: KeyEnter, Mods: ModCtrl | ModNumLock}, "ctrl+enter"},
+		{KeyEvent{Key: Key(99)}, "Key(99)"},
+	}
+	for _, tt := range tests {
+		if got := tt.ev.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.ev, got, tt.want)
+		}
+	}
+}
+
+func TestMod_String(t *testing.T) {
+	if got := (ModShift | ModCtrl | ModSuper).String(); got != "ctrl+shift+super" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := Mod(0).String(); got != "" {
+		t.Errorf("String() = %q, want empty", got)
+	}
+}
+
+func TestKittyKeyboard(t *testing.T) {
+	if got := KittyKeyboardPush(KittyDisambiguate | KittyReportEvents); got != "\x1b[>3u" {
+		t.Errorf("KittyKeyboardPush() = %q", got)
+	}
+	if got := KittyKeyboardPop(1); got != "\x1b[<1u" {
+		t.Errorf("KittyKeyboardPop() = %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package ptyx
+
+import "fmt"
+
+// MouseTracking selects which mouse events the terminal reports.
+type MouseTracking int
+
+const (
+	// MouseX10 reports button presses only.
+	MouseX10 MouseTracking = iota
+	// MouseNormal reports presses and releases.
+	MouseNormal
+	// MouseButtonMotion also reports motion while a button is held.
+	MouseButtonMotion
+	// MouseAnyMotion reports all motion.
+	MouseAnyMotion
+)
+
+// MouseEncoding selects how the terminal encodes mouse reports. SGR is the
+// only one without a limit on coordinates and with distinct releases.
+type MouseEncoding int
+
+const (
+	MouseEncodingSGR MouseEncoding = iota
+	MouseEncodingURXVT
+	// MouseEncodingX10 is the original encoding, limited to 223 columns and
+	// rows.
+	MouseEncodingX10
+)
+
+var mouseTrackingModes = [...]int{ModeMouseX10, ModeMouseNormal, ModeMouseButton, ModeMouseAny}
+
+// mouseModes returns the DEC private modes to set for t and e.
+func mouseModes(t MouseTracking, e MouseEncoding) ([]int, error) {
+	if t < MouseX10 || t > MouseAnyMotion {
+		return nil, fmt.Errorf("ptyx: invalid mouse tracking %d", t)
+	}
+	modes := []int{mouseTrackingModes[t]}
+	switch e {
+	case MouseEncodingSGR:
+		modes = append(modes, ModeMouseSGR)
+	case MouseEncodingURXVT:
+		modes = append(modes, ModeMouseURXVT)
+	case MouseEncodingX10:
+	default:
+		return nil, fmt.Errorf("ptyx: invalid mouse encoding %d", e)
+	}
+	return modes, nil
+}
+
+// EnableMouse turns on mouse reporting. Restore and Close turn it off again,
+// as does DisableMouse.
+func (c *console) EnableMouse(t MouseTracking, e MouseEncoding) error {
+	modes, err := mouseModes(t, e)
+	if err != nil {
+		return err
+	}
+	return c.setMode("mouse", DECSET(modes...), DECRST(modes...))
+}
+
+func (c *console) DisableMouse() error { return c.clearMode("mouse") }
+
+type MouseButton int
+
+const (
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+	MouseBackward
+	MouseForward
+)
+
+var mouseButtonNames = [...]string{"none", "left", "middle", "right", "wheelup", "wheeldown", "wheelleft", "wheelright", "backward", "forward"}
+
+func (b MouseButton) String() string {
+	if b >= 0 && int(b) < len(mouseButtonNames) {
+		return mouseButtonNames[b]
+	}
+	return fmt.Sprintf("MouseButton(%d)", int(b))
+}
+
+type MouseAction int
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	MouseMotion
+)
+
+func (a MouseAction) String() string {
+	switch a {
+	case MousePress:
+		return "press"
+	case MouseRelease:
+		return "release"
+	case MouseMotion:
+		return "motion"
+	}
+	return fmt.Sprintf("MouseAction(%d)", int(a))
+}
+
+// MouseEvent is a decoded mouse report. X and Y are 1-based, like CUP. Wheel
+// events are presses. Releases in the X10 and URXVT encodings do not say
+// which button was released, so Button is MouseNone.
+type MouseEvent struct {
+	X, Y   int
+	Button MouseButton
+	Action MouseAction
+	Mods   Mod
+}
+
+func (MouseEvent) inputEvent() {}
+
+func (e MouseEvent) String() string {
+	s := fmt.Sprintf("%s %s at %d,%d", e.Button, e.Action, e.X, e.Y)
+	if m := e.Mods.String(); m != "" {
+		s = m + "+" + s
+	}
+	return s
+}
+
+// decodeMouse decodes the button byte shared by all encodings, with the X10
+// offset of 32 already removed.
+func decodeMouse(cb, x, y int, release bool) MouseEvent {
+	ev := MouseEvent{X: x, Y: y}
+	if cb&4 != 0 {
+		ev.Mods |= ModShift
+	}
+	if cb&8 != 0 {
+		ev.Mods |= ModAlt
+	}
+	if cb&16 != 0 {
+		ev.Mods |= ModCtrl
+	}
+	low := cb & 3
+	switch {
+	case cb&128 != 0:
+		ev.Button = MouseBackward + MouseButton(low)
+	case cb&64 != 0:
+		ev.Button = MouseWheelUp + MouseButton(low)
+	case low == 3:
+		ev.Button = MouseNone
+		release = release || cb&32 == 0
+	default:
+		ev.Button = MouseLeft + MouseButton(low)
+	}
+	switch {
+	case cb&32 != 0:
+		ev.Action = MouseMotion
+	case release:
+		ev.Action = MouseRelease
+	}
+	return ev
+}
@@ -0,0 +1,86 @@
+package ptyx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMouseModes(t *testing.T) {
+	tests := []struct {
+		tracking MouseTracking
+		encoding MouseEncoding
+		want     []int
+		wantErr  bool
+	}{
+		{MouseX10, MouseEncodingX10, []int{ModeMouseX10}, false},
+		{MouseNormal, MouseEncodingSGR, []int{ModeMouseNormal, ModeMouseSGR}, false},
+		{MouseButtonMotion, MouseEncodingURXVT, []int{ModeMouseButton, ModeMouseURXVT}, false},
+		{MouseAnyMotion, MouseEncodingSGR, []int{ModeMouseAny, ModeMouseSGR}, false},
+		{MouseTracking(7), MouseEncodingSGR, nil, true},
+		{MouseNormal, MouseEncoding(7), nil, true},
+	}
+	for _, tt := range tests {
+		got, err := mouseModes(tt.tracking, tt.encoding)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mouseModes(%d, %d) = %v, %v, want %v", tt.tracking, tt.encoding, got, err, tt.want)
+		}
+	}
+}
+
+func TestInputReader_Mouse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []InputEvent
+	}{
+		{"SGRPressRelease", "\x1b[<0;10;5M\x1b[<0;10;5m", []InputEvent{
+			MouseEvent{X: 10, Y: 5, Button: MouseLeft},
+			MouseEvent{X: 10, Y: 5, Button: MouseLeft, Action: MouseRelease},
+		}},
+		{"SGRLargeCoordinates", "\x1b[<2;300;400M", []InputEvent{MouseEvent{X: 300, Y: 400, Button: MouseRight}}},
+		{"SGRDragWithMods", "\x1b[<52;3;4M", []InputEvent{MouseEvent{X: 3, Y: 4, Button: MouseLeft, Action: MouseMotion, Mods: ModShift | ModCtrl}}},
+		{"SGRMotionNoButton", "\x1b[<35;1;1M", []InputEvent{MouseEvent{X: 1, Y: 1, Action: MouseMotion}}},
+		{"SGRWheel", "\x1b[<64;2;2M\x1b[<65;2;2M\x1b[<66;2;2M", []InputEvent{
+			MouseEvent{X: 2, Y: 2, Button: MouseWheelUp},
+			MouseEvent{X: 2, Y: 2, Button: MouseWheelDown},
+			MouseEvent{X: 2, Y: 2, Button: MouseWheelLeft},
+		}},
+		{"SGRExtraButtons", "\x1b[<136;1;1M\x1b[<129;1;1M", []InputEvent{
+			MouseEvent{X: 1, Y: 1, Button: MouseBackward, Mods: ModAlt},
+			MouseEvent{X: 1, Y: 1, Button: MouseForward},
+		}},
+		{"URXVT", "\x1b[32;10;20M\x1b[35;10;20M", []InputEvent{
+			MouseEvent{X: 10, Y: 20, Button: MouseLeft},
+			MouseEvent{X: 10, Y: 20, Action: MouseRelease},
+		}},
+		{"X10", "\x1b[M !!\x1b[M#!!", []InputEvent{
+			MouseEvent{X: 1, Y: 1, Button: MouseLeft},
+			MouseEvent{X: 1, Y: 1, Action: MouseRelease},
+		}},
+		{"X10Truncated", "\x1b[M !", nil},
+		{"MixedWithKeys", "a\x1b[<1;1;1Mb", []InputEvent{
+			char('a', 0), MouseEvent{X: 1, Y: 1, Button: MouseMiddle}, char('b', 0),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readEvents(t, NewInputReader(&chunkReader{chunks: []string{tt.in}}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMouseEvent_String(t *testing.T) {
+	ev := MouseEvent{X: 3, Y: 4, Button: MouseLeft, Action: MouseMotion, Mods: ModCtrl}
+	if got := ev.String(); got != "ctrl+left motion at 3,4" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := MouseButton(42).String(); got != "MouseButton(42)" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := MouseAction(9).String(); got != "MouseAction(9)" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package ptyx
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -12,9 +13,9 @@ const (
 )
 
 type mux struct {
-	cancel func()
-	wg     sync.WaitGroup
-	closeStdinOnce sync.Once
+	cancel  func()
+	wg      sync.WaitGroup
+	stopped atomic.Bool
 
 	mu    sync.Mutex
 	state int
@@ -41,15 +42,16 @@ func (m *mux) Start(c Console, s Session) error {
 
 	go func() {
 		defer m.wg.Done()
-		_, _ = io.Copy(s.PtyWriter(), c.In())
-		m.closeStdinOnce.Do(func() { _ = s.CloseStdin() })
+		// Only the end of the console's input is passed on; Stop and read
+		// errors leave the child's input open.
+		if _, err := io.Copy(s.PtyWriter(), c.In()); err == nil && !m.stopped.Load() {
+			_ = s.SendEOF()
+		}
 	}()
 
 	go func() {
 		defer m.wg.Done()
 		_, _ = io.Copy(c.Out(), s.PtyReader())
-
-		m.closeStdinOnce.Do(func() { _ = s.CloseStdin() })
 	}()
 	return nil
 }
@@ -60,6 +62,7 @@ func (m *mux) Stop() error {
 
 	if m.state == muxRunning {
 		m.state = muxStopped
+		m.stopped.Store(true)
 		if m.c != nil {
 			if closer, ok := m.c.In().(io.Closer); ok {
 				_ = closer.Close()
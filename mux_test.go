@@ -1,6 +1,7 @@
 package ptyx
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"testing"
@@ -28,9 +29,9 @@ func TestMux(t *testing.T) {
 		ptyOutR, ptyOutW := io.Pipe()
 		s.ptyOut = ptyOutR
 
-		closedStdin := make(chan struct{})
-		s.closeStdinFunc = func() error {
-			close(closedStdin)
+		sentEOF := make(chan struct{})
+		s.sendEOFFunc = func() error {
+			close(sentEOF)
 			return nil
 		}
 
@@ -40,7 +41,7 @@ func TestMux(t *testing.T) {
 		}
 
 		select {
-		case <-closedStdin:
+		case <-sentEOF:
 		case <-time.After(1 * time.Second):
 			t.Fatal("timed out waiting for console->pty copy to complete")
 		}
@@ -122,6 +123,35 @@ func TestMux(t *testing.T) {
 		}
 	})
 
+	t.Run("NoEOFWithoutEndOfInput", func(t *testing.T) {
+		open, _ := io.Pipe()
+		tests := []struct {
+			name string
+			in   io.ReadCloser
+		}{
+			{"Stop", open},
+			{"ReadError", &mockCloser{&errorReader{}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c := &mockConsole{in: tt.in, outBuf: &bytes.Buffer{}}
+				s := newMockSession("")
+				s.sendEOFFunc = func() error {
+					t.Error("SendEOF() called without the end of console input")
+					return nil
+				}
+
+				m := NewMux()
+				if err := m.Start(c, s); err != nil {
+					t.Fatalf("Mux.Start() failed: %v", err)
+				}
+				if err := m.Stop(); err != nil {
+					t.Fatalf("Mux.Stop() failed: %v", err)
+				}
+			})
+		}
+	})
+
 	t.Run("FailingPtyReader", func(t *testing.T) {
 		c := newMockConsole("")
 		s := newMockSession("")
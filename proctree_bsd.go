@@ -0,0 +1,12 @@
+//go:build darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+// Without /proc only the process group is killed.
+func processTree(leader int) []int { return nil }
+
+func exitTree(leader int) []int { return nil }
+
+func setSubreaper() error { return ErrUnsupported }
+
+func reap(pids []int) {}
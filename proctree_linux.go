@@ -0,0 +1,115 @@
+//go:build linux
+
+package ptyx
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func setSubreaper() error {
+	return unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0)
+}
+
+// processTree returns every process other than leader that is in the
+// session leader started or descends from it, found by scanning /proc.
+func processTree(leader int) []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	parent := map[int]int{}
+	var pids []int
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == leader {
+			continue
+		}
+		ppid, sid, ok := readStat(pid)
+		if !ok {
+			continue
+		}
+		parent[pid] = ppid
+		if sid == leader {
+			pids = append(pids, pid)
+		}
+	}
+	for pid := range parent {
+		if isDescendant(pid, leader, parent) && !containsInt(pids, pid) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// exitTree waits for leader to exit without reaping it, so that its pid
+// and session id still name it, and returns the processes it left behind.
+func exitTree(leader int) []int {
+	var info unix.Siginfo
+	for unix.Waitid(unix.P_PID, leader, &info, unix.WEXITED|unix.WNOWAIT, nil) == unix.EINTR {
+	}
+	return processTree(leader)
+}
+
+func isDescendant(pid, ancestor int, parent map[int]int) bool {
+	for i := 0; i < len(parent) && pid > 1; i++ {
+		pid = parent[pid]
+		if pid == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// readStat returns the parent pid and session id from /proc/<pid>/stat.
+func readStat(pid int) (ppid, sid int, ok bool) {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+	// The command name is in parentheses and may contain spaces.
+	i := bytes.LastIndexByte(b, ')')
+	if i < 0 {
+		return 0, 0, false
+	}
+	f := bytes.Fields(b[i+1:])
+	if len(f) < 4 {
+		return 0, 0, false
+	}
+	ppid, err1 := strconv.Atoi(string(f[1]))
+	sid, err2 := strconv.Atoi(string(f[3]))
+	return ppid, sid, err1 == nil && err2 == nil
+}
+
+// reap collects killed descendants that were reparented to this process
+// because it is a subreaper.
+func reap(pids []int) {
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for len(pids) > 0 && time.Now().Before(deadline) {
+		rest := pids[:0]
+		for _, pid := range pids {
+			var ws unix.WaitStatus
+			n, err := unix.Wait4(pid, &ws, unix.WNOHANG, nil)
+			if n == 0 && err == nil {
+				rest = append(rest, pid)
+			}
+		}
+		pids = rest
+		if len(pids) > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
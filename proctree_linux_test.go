@@ -0,0 +1,162 @@
+//go:build linux
+
+package ptyx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadStat(t *testing.T) {
+	ppid, sid, ok := readStat(os.Getpid())
+	if !ok {
+		t.Fatal("readStat(self) failed")
+	}
+	if ppid != os.Getppid() {
+		t.Errorf("ppid = %d, want %d", ppid, os.Getppid())
+	}
+	if sid <= 0 {
+		t.Errorf("sid = %d", sid)
+	}
+	if _, _, ok := readStat(-1); ok {
+		t.Error("readStat(-1) should fail")
+	}
+}
+
+func TestIsDescendant(t *testing.T) {
+	parent := map[int]int{10: 1, 11: 10, 12: 11, 20: 1}
+	tests := []struct {
+		pid, ancestor int
+		want          bool
+	}{
+		{11, 10, true},
+		{12, 10, true},
+		{20, 10, false},
+		{10, 10, false},
+	}
+	for _, tt := range tests {
+		if got := isDescendant(tt.pid, tt.ancestor, parent); got != tt.want {
+			t.Errorf("isDescendant(%d, %d) = %v, want %v", tt.pid, tt.ancestor, got, tt.want)
+		}
+	}
+}
+
+func TestUnixSession_KillProcessTree(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SpawnOpts
+	}{
+		{"Kill", SpawnOpts{KillProcessTree: true}},
+		{"Subreaper", SpawnOpts{KillProcessTree: true, Subreaper: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.Subreaper && os.Getenv("PTYX_SUBREAPER_TEST") != "1" {
+				// Becoming a subreaper cannot be undone, so keep it out of
+				// this test binary.
+				cmd := exec.Command(os.Args[0], "-test.run=^TestUnixSession_KillProcessTree$/^Subreaper$", "-test.v")
+				cmd.Env = append(os.Environ(), "PTYX_SUBREAPER_TEST=1")
+				out, err := cmd.CombinedOutput()
+				if err != nil || !strings.Contains(string(out), "--- PASS: TestUnixSession_KillProcessTree/Subreaper") {
+					t.Fatalf("subreaper test process failed: %v\n%s", err, out)
+				}
+				return
+			}
+			opts := tt.opts
+			opts.Prog = "sh"
+			// set -m moves the background job to its own process group, so
+			// killing the child's group alone would leave it running.
+			opts.Args = []string{"-c", "set -m; sleep 300 & echo bg $!; wait"}
+			s, err := Spawn(context.Background(), opts)
+			if err != nil {
+				if errors.Is(err, exec.ErrNotFound) {
+					t.Skipf("could not find 'sh', skipping test: %v", err)
+				}
+				t.Fatalf("Spawn failed: %v", err)
+			}
+			defer s.Close()
+
+			line, err := readPTYOneLine(s.PtyReader())
+			if err != nil {
+				t.Fatalf("reading background pid failed: %v", err)
+			}
+			bg, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "bg ")))
+			if err != nil {
+				t.Fatalf("unexpected output %q", line)
+			}
+
+			if err := s.Kill(); err != nil {
+				t.Fatalf("Kill() failed: %v", err)
+			}
+			_ = s.Wait()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for processRunning(bg) {
+				if time.Now().After(deadline) {
+					t.Fatalf("background job %d survived Kill()", bg)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			if err := s.Kill(); !errors.Is(err, os.ErrProcessDone) {
+				t.Errorf("Kill() after Wait() = %v, want os.ErrProcessDone", err)
+			}
+		})
+	}
+}
+
+func TestUnixSession_KillProcessTree_AfterExit(t *testing.T) {
+	// The shell exits at once, leaving its background job behind.
+	s, err := Spawn(context.Background(), SpawnOpts{
+		Prog:            "sh",
+		Args:            []string{"-c", "set -m; sleep 300 & echo bg $!"},
+		KillProcessTree: true,
+	})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+
+	line, err := readPTYOneLine(s.PtyReader())
+	if err != nil {
+		t.Fatalf("reading background pid failed: %v", err)
+	}
+	bg, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "bg ")))
+	if err != nil {
+		t.Fatalf("unexpected output %q", line)
+	}
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if !processRunning(bg) {
+		t.Fatalf("background job %d exited with the shell", bg)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for processRunning(bg) {
+		if time.Now().After(deadline) {
+			t.Fatalf("background job %d survived Close()", bg)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// processRunning reports whether pid exists and is not a zombie.
+func processRunning(pid int) bool {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	i := strings.LastIndexByte(string(b), ')')
+	return i >= 0 && !strings.HasPrefix(string(b[i+1:]), " Z")
+}
@@ -0,0 +1,13 @@
+//go:build darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import "os"
+
+// Only Linux supports more than one ptmx device.
+func openPTYFrom(ptmx string) (*os.File, *os.File, error) {
+	if ptmx != "/dev/ptmx" {
+		return nil, nil, ErrUnsupported
+	}
+	return openPTY()
+}
@@ -0,0 +1,20 @@
+package ptyx
+
+import "os"
+
+// PTY is a pseudo-terminal pair that is not attached to any process.
+// Name is the path of the slave device, such as /dev/pts/3.
+type PTY struct {
+	Master *os.File
+	Slave  *os.File
+	Name   string
+}
+
+// Close closes both ends of the pair.
+func (p *PTY) Close() error {
+	errSlave := p.Slave.Close()
+	if err := p.Master.Close(); err != nil {
+		return err
+	}
+	return errSlave
+}
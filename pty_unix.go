@@ -0,0 +1,31 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import "golang.org/x/sys/unix"
+
+// Open creates a new PTY pair. The slave is not the controlling terminal of
+// this process.
+func Open() (*PTY, error) {
+	m, s, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	return &PTY{Master: m, Slave: s, Name: s.Name()}, nil
+}
+
+func (p *PTY) Resize(cols, rows int) error { return setWinsize(int(p.Master.Fd()), cols, rows) }
+func (p *PTY) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	return setWinsizePixels(int(p.Master.Fd()), cols, rows, xpixel, ypixel)
+}
+
+func (p *PTY) Size() (cols, rows int, err error) {
+	ws, err := unix.IoctlGetWinsize(int(p.Master.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+func (p *PTY) Termios() (Termios, error)  { return getTermios(int(p.Slave.Fd())) }
+func (p *PTY) SetTermios(t Termios) error { return setTermios(int(p.Slave.Fd()), t) }
@@ -0,0 +1,56 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	p, err := Open()
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer p.Close()
+
+	if !strings.HasPrefix(p.Name, "/dev/") || p.Name != p.Slave.Name() {
+		t.Errorf("Name = %q, slave name = %q", p.Name, p.Slave.Name())
+	}
+
+	if err := p.Resize(132, 43); err != nil {
+		t.Fatalf("Resize() failed: %v", err)
+	}
+	cols, rows, err := p.Size()
+	if err != nil || cols != 132 || rows != 43 {
+		t.Errorf("Size() = %d, %d, %v, want 132, 43", cols, rows, err)
+	}
+	if err := p.ResizeWithPixels(100, 30, 800, 480); err != nil {
+		t.Fatalf("ResizeWithPixels() failed: %v", err)
+	}
+	if cols, rows, _ := p.Size(); cols != 100 || rows != 30 {
+		t.Errorf("Size() after ResizeWithPixels = %d, %d, want 100, 30", cols, rows)
+	}
+
+	tio, err := p.Termios()
+	if err != nil {
+		t.Fatalf("Termios() failed: %v", err)
+	}
+	tio.Echo = false
+	if err := p.SetTermios(tio); err != nil {
+		t.Fatalf("SetTermios() failed: %v", err)
+	}
+
+	if _, err := io.WriteString(p.Slave, "from slave\n"); err != nil {
+		t.Fatalf("writing slave failed: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, err := p.Master.Read(buf)
+	if err != nil {
+		t.Fatalf("reading master failed: %v", err)
+	}
+	if got := string(buf[:n]); got != "from slave\r\n" {
+		t.Errorf("master read %q, want %q", got, "from slave\r\n")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package ptyx
+
+// Open is not available on Windows, where ConPTY has no slave device; use
+// NewConPty for a pseudo console.
+func Open() (*PTY, error) { return nil, ErrUnsupported }
+
+func (p *PTY) Resize(cols, rows int) error                           { return ErrUnsupported }
+func (p *PTY) ResizeWithPixels(cols, rows, xpixel, ypixel int) error { return ErrUnsupported }
+func (p *PTY) Size() (cols, rows int, err error)                     { return 0, 0, ErrUnsupported }
+func (p *PTY) Termios() (Termios, error)                             { return Termios{}, ErrUnsupported }
+func (p *PTY) SetTermios(t Termios) error                            { return ErrUnsupported }
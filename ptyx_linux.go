@@ -3,8 +3,10 @@
 package ptyx
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"unsafe"
 
 	"golang.org/x/sys/unix"
@@ -16,8 +18,14 @@ var (
 	unixClose   = unix.Close
 )
 
-func openPTY() (*os.File, *os.File, error) {
-	masterFd, err := unixOpen("/dev/ptmx", unix.O_RDWR|unix.O_CLOEXEC, 0)
+const defaultPtmx = "/dev/ptmx"
+
+func openPTY() (*os.File, *os.File, error) { return openPTYFrom(defaultPtmx) }
+
+// openPTYFrom opens a PTY pair from the given ptmx device, which may belong
+// to a devpts instance other than the one mounted on /dev/pts.
+func openPTYFrom(ptmx string) (*os.File, *os.File, error) {
+	masterFd, err := unixOpen(ptmx, unix.O_RDWR|unix.O_CLOEXEC, 0)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -29,7 +37,7 @@ func openPTY() (*os.File, *os.File, error) {
 		_ = unixClose(masterFd)
 		return nil, nil, fmt.Errorf("ioctl(TIOCGPTN): %w", err)
 	}
-	slaveName := fmt.Sprintf("/dev/pts/%d", ptn)
+	slaveName := filepath.Join(ptsDir(ptmx), fmt.Sprint(ptn))
 
 	var p int
 	_, _, errno = unixSyscall(unix.SYS_IOCTL, uintptr(masterFd), unix.TIOCSPTLCK, uintptr(unsafe.Pointer(&p)))
@@ -39,11 +47,33 @@ func openPTY() (*os.File, *os.File, error) {
 		return nil, nil, fmt.Errorf("ioctl(TIOCSPTLCK): %w", err)
 	}
 
-	slaveFd, err := unixOpen(slaveName, unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
-	if err != nil {
+	// TIOCGPTPEER (Linux 4.13+) opens the slave through the master, so it
+	// works whichever devpts instance is mounted where and cannot race with
+	// the path being replaced.
+	flags := unix.O_RDWR | unix.O_NOCTTY | unix.O_CLOEXEC
+	r1, _, errno := unixSyscall(unix.SYS_IOCTL, uintptr(masterFd), unix.TIOCGPTPEER, uintptr(flags))
+	slaveFd := int(r1)
+	switch {
+	case errno == 0:
+	case errors.Is(errno, unix.EINVAL), errors.Is(errno, unix.ENOTTY):
+		slaveFd, err = unixOpen(slaveName, flags, 0)
+		if err != nil {
+			_ = unixClose(masterFd)
+			return nil, nil, err
+		}
+	default:
 		_ = unixClose(masterFd)
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("ioctl(TIOCGPTPEER): %w", errno)
 	}
 
-	return os.NewFile(uintptr(masterFd), "pty-master"), os.NewFile(uintptr(slaveFd), "pty-slave"), nil
+	return os.NewFile(uintptr(masterFd), ptmx), os.NewFile(uintptr(slaveFd), slaveName), nil
+}
+
+// ptsDir returns where the slaves of ptmx live: /dev/pts for /dev/ptmx, or
+// the directory of a devpts instance's own ptmx node.
+func ptsDir(ptmx string) string {
+	if dir := filepath.Dir(ptmx); dir != "/dev" {
+		return dir
+	}
+	return "/dev/pts"
 }
@@ -3,7 +3,9 @@
 package ptyx
 
 import (
+	"context"
 	"errors"
+	"os"
 	"strings"
 	"syscall"
 	"testing"
@@ -67,6 +69,7 @@ func TestOpenPTY_ErrorPaths(t *testing.T) {
 	})
 
 	t.Run("OpenSlaveError", func(t *testing.T) {
+		forcePathFallback(t)
 		originalUnixOpen := unixOpen
 		unixOpen = func(path string, mode int, perm uint32) (int, error) {
 			if strings.HasPrefix(path, "/dev/pts/") {
@@ -84,4 +87,77 @@ func TestOpenPTY_ErrorPaths(t *testing.T) {
 			t.Errorf("Expected 'mock open slave error', got %v", err)
 		}
 	})
+
+	t.Run("IoctlTiocgptpeerError", func(t *testing.T) {
+		originalUnixSyscall := unixSyscall
+		unixSyscall = func(trap, a1, a2, a3 uintptr) (r1, r2 uintptr, err syscall.Errno) {
+			if a2 == unix.TIOCGPTPEER {
+				return 0, 0, syscall.EIO
+			}
+			return originalUnixSyscall(trap, a1, a2, a3)
+		}
+		t.Cleanup(func() { unixSyscall = originalUnixSyscall })
+
+		_, _, err := openPTY()
+		if err == nil {
+			t.Fatal("openPTY should have failed but did not")
+		}
+		if !strings.Contains(err.Error(), "ioctl(TIOCGPTPEER)") {
+			t.Errorf("Expected 'ioctl(TIOCGPTPEER)' error, got %v", err)
+		}
+	})
+}
+
+// forcePathFallback makes TIOCGPTPEER fail as it does before Linux 4.13.
+func forcePathFallback(t *testing.T) {
+	originalUnixSyscall := unixSyscall
+	unixSyscall = func(trap, a1, a2, a3 uintptr) (r1, r2 uintptr, err syscall.Errno) {
+		if a2 == unix.TIOCGPTPEER {
+			return 0, 0, syscall.EINVAL
+		}
+		return originalUnixSyscall(trap, a1, a2, a3)
+	}
+	t.Cleanup(func() { unixSyscall = originalUnixSyscall })
+}
+
+func TestOpenPTY_PathFallback(t *testing.T) {
+	forcePathFallback(t)
+	m, s, err := openPTY()
+	if err != nil {
+		t.Fatalf("openPTY() failed: %v", err)
+	}
+	defer m.Close()
+	defer s.Close()
+	if !strings.HasPrefix(s.Name(), "/dev/pts/") {
+		t.Errorf("slave name = %q", s.Name())
+	}
+	if _, err := unix.IoctlGetTermios(int(s.Fd()), unix.TCGETS); err != nil {
+		t.Errorf("slave is not a terminal: %v", err)
+	}
+}
+
+func TestPtsDir(t *testing.T) {
+	tests := []struct{ ptmx, want string }{
+		{"/dev/ptmx", "/dev/pts"},
+		{"/dev/pts/ptmx", "/dev/pts"},
+		{"/run/container/pts/ptmx", "/run/container/pts"},
+	}
+	for _, tt := range tests {
+		if got := ptsDir(tt.ptmx); got != tt.want {
+			t.Errorf("ptsDir(%q) = %q, want %q", tt.ptmx, got, tt.want)
+		}
+	}
+}
+
+func TestSpawn_PtmxPath(t *testing.T) {
+	if _, err := Spawn(context.Background(), SpawnOpts{Prog: "true", PtmxPath: "/nonexistent/ptmx"}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Spawn() with missing ptmx = %v, want os.ErrNotExist", err)
+	}
+
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "true", PtmxPath: "/dev/ptmx"})
+	if err != nil {
+		t.Fatalf("Spawn() with /dev/ptmx failed: %v", err)
+	}
+	defer s.Close()
+	_ = s.Wait()
 }
@@ -5,23 +5,89 @@ package ptyx
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"sync"
+	"syscall"
+	"time"
 
 	"golang.org/x/sys/unix"
 )
 
 type unixSession struct {
-	cmd    *exec.Cmd
-	master *os.File
+	ctx       context.Context
+	cmd       *exec.Cmd
+	master    *os.File
+	pipes     stdioPipes
+	killTree  bool
+	subreaper bool
+
+	waitOnce sync.Once
+	waitErr  error
+	done     chan struct{}
+
+	// leftover is what was left of the process tree when the child exited,
+	// for Close and Kill to clean up.
+	leftMu   sync.Mutex
+	leftover []int
+
+	trackKeys bool
+	keys      keyModeTracker
 }
 
-func Spawn(ctx context.Context, opts SpawnOpts) (sess Session, err error) {
+func Spawn(ctx context.Context, opts SpawnOpts) (Session, error) {
 	if opts.Prog == "" {
 		return nil, errors.New("ptyx: empty program")
 	}
-	m, s, err := openPTY()
+	cmd := exec.CommandContext(ctx, opts.Prog, opts.Args...)
+	cmd.Env = opts.Env
+	if opts.Dir != "" {
+		cmd.Dir = opts.Dir
+	}
+	cmd.SysProcAttr = newSysProcAttr()
+	return start(ctx, cmd, opts)
+}
+
+// StartCmd starts cmd on a new PTY. Prog, Args, Env and Dir in opts are
+// ignored in favour of cmd's own fields. Streams cmd already has set are kept
+// as if passed in opts, and Setsid and Setctty are added to a copy of
+// cmd.SysProcAttr. A context given to exec.CommandContext still stops cmd,
+// but the ExitError does not report it as Canceled, as the context cannot
+// be seen from cmd; use Spawn when that matters.
+func StartCmd(cmd *exec.Cmd, opts SpawnOpts) (Session, error) {
+	if cmd.Process != nil {
+		return nil, errors.New("ptyx: exec: already started")
+	}
+	attr, err := mergeSysProcAttr(cmd.SysProcAttr)
+	if err != nil {
+		return nil, err
+	}
+	cmd.SysProcAttr = attr
+	if opts.Stdin == nil {
+		opts.Stdin = cmd.Stdin
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = cmd.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = cmd.Stderr
+	}
+	return start(context.Background(), cmd, opts)
+}
+
+func start(ctx context.Context, cmd *exec.Cmd, opts SpawnOpts) (sess Session, err error) {
+	if opts.Subreaper {
+		if err := setSubreaper(); err != nil {
+			return nil, fmt.Errorf("ptyx: set child subreaper: %w", err)
+		}
+	}
+	ptmx := opts.PtmxPath
+	if ptmx == "" {
+		ptmx = "/dev/ptmx"
+	}
+	m, s, err := openPTYFrom(ptmx)
 	if err != nil {
 		return nil, err
 	}
@@ -32,16 +98,24 @@ func Spawn(ctx context.Context, opts SpawnOpts) (sess Session, err error) {
 		}
 	}()
 
-	cmd := exec.CommandContext(ctx, opts.Prog, opts.Args...)
-	cmd.Env = opts.Env
-	if opts.Dir != "" {
-		cmd.Dir = opts.Dir
+	pipes, childFiles, err := setupStdio(cmd, s, opts)
+	if err != nil {
+		return nil, err
 	}
-	cmd.Stdin, cmd.Stdout, cmd.Stderr = s, s, s
-	cmd.SysProcAttr = newSysProcAttr()
+	defer closeFiles(childFiles)
+	defer func() {
+		if err != nil {
+			pipes.close()
+		}
+	}()
 
 	if opts.Cols > 0 && opts.Rows > 0 {
-		_ = setWinsize(int(m.Fd()), opts.Cols, opts.Rows)
+		_ = setWinsizePixels(int(m.Fd()), opts.Cols, opts.Rows, opts.XPixel, opts.YPixel)
+	}
+	if opts.Termios != nil {
+		if err = setTermios(int(s.Fd()), *opts.Termios); err != nil {
+			return nil, fmt.Errorf("ptyx: set termios: %w", err)
+		}
 	}
 
 	if err = cmd.Start(); err != nil {
@@ -49,32 +123,258 @@ func Spawn(ctx context.Context, opts SpawnOpts) (sess Session, err error) {
 	}
 	_ = s.Close()
 
-	return &unixSession{cmd: cmd, master: m}, nil
+	return &unixSession{
+		ctx:       ctx,
+		cmd:       cmd,
+		master:    m,
+		pipes:     pipes,
+		killTree:  opts.KillProcessTree,
+		subreaper: opts.Subreaper,
+		done:      make(chan struct{}),
+		trackKeys: opts.TrackKeyModes,
+	}, nil
 }
 
-func (s *unixSession) PtyReader() io.Reader { return s.master }
+func (s *unixSession) PtyReader() io.Reader {
+	if s.trackKeys {
+		return io.TeeReader(s.master, &s.keys)
+	}
+	return s.master
+}
 func (s *unixSession) PtyWriter() io.Writer { return s.master }
+func (s *unixSession) KeyModes() KeyModes   { return s.keys.get() }
+func (s *unixSession) SendKeys(keys ...any) error {
+	return s.keys.sendKeys(s.master, keys)
+}
 func (s *unixSession) Resize(cols, rows int) error { return setWinsize(int(s.master.Fd()), cols, rows) }
+func (s *unixSession) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	return setWinsizePixels(int(s.master.Fd()), cols, rows, xpixel, ypixel)
+}
 func (s *unixSession) Wait() error {
-	err := s.cmd.Wait()
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		return &ExitError{
-			ExitCode:   exitErr.ExitCode(),
-			waitStatus: exitErr.Sys(),
+	s.waitOnce.Do(func() {
+		defer close(s.done)
+		if s.killTree {
+			s.leftover = exitTree(s.cmd.Process.Pid)
+		}
+		err := s.cmd.Wait()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			err = newExitError(exitErr)
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() && s.ctx.Err() != nil {
+				err = canceledError(err, s.ctx.Err())
+			}
 		}
+		s.waitErr = err
+	})
+	return s.waitErr
+}
+func (s *unixSession) Kill() error {
+	if s.killTree {
+		if s.exited() {
+			s.killLeftover()
+			return os.ErrProcessDone
+		}
+		return s.killProcessTree()
 	}
-	return err
+	return s.cmd.Process.Kill()
+}
+
+func (s *unixSession) Close() error {
+	if s.killTree {
+		if s.exited() {
+			s.killLeftover()
+		} else {
+			_ = s.killProcessTree()
+		}
+	}
+	s.pipes.close()
+	return s.master.Close()
+}
+
+func (s *unixSession) StdinPipe() io.WriteCloser {
+	if s.pipes.stdin == nil {
+		return nil
+	}
+	return s.pipes.stdin
+}
+
+func (s *unixSession) StdoutPipe() io.ReadCloser {
+	if s.pipes.stdout == nil {
+		return nil
+	}
+	return s.pipes.stdout
+}
+
+func (s *unixSession) StderrPipe() io.ReadCloser {
+	if s.pipes.stderr == nil {
+		return nil
+	}
+	return s.pipes.stderr
 }
-func (s *unixSession) Kill() error { return s.cmd.Process.Kill() }
-func (s *unixSession) Close() error { return s.master.Close() }
 func (s *unixSession) Pid() int { return s.cmd.Process.Pid }
 
 func (s *unixSession) CloseStdin() error {
 	return s.master.Close()
 }
 
-func setWinsize(fd int, cols, rows int) error {
-	ws := &unix.Winsize{Col: uint16(cols), Row: uint16(rows)}
+// SendEOF writes the VEOF character, leaving the master open so the rest of
+// the child's output can still be read. In canonical mode the line
+// discipline turns it into end of file for the child's next read; if a
+// partial line is pending it only delivers that line, so end input with a
+// newline first. In raw mode the byte (^D by default) reaches the child
+// as is, which most raw mode programs treat as end of input.
+func (s *unixSession) SendEOF() error {
+	t, err := s.Termios()
+	if err != nil {
+		return err
+	}
+	eof := t.EOF
+	if eof == 0 {
+		eof = DefaultTermios.EOF
+	}
+	_, err = s.master.Write([]byte{eof})
+	return err
+}
+
+func (s *unixSession) Termios() (Termios, error) { return getTermios(int(s.master.Fd())) }
+func (s *unixSession) SetTermios(t Termios) error  { return setTermios(int(s.master.Fd()), t) }
+
+func (s *unixSession) Signal(sig os.Signal) error { return s.cmd.Process.Signal(sig) }
+
+// SignalForeground delivers sig to the foreground process group of the PTY,
+// which is what the terminal driver does for ^C, ^Z and window size changes.
+func (s *unixSession) SignalForeground(sig os.Signal) error {
+	ssig, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("ptyx: unsupported signal %v", sig)
+	}
+	pgrp, err := s.foregroundPgrp()
+	if err != nil {
+		return err
+	}
+	return unix.Kill(-pgrp, ssig)
+}
+
+// Terminate signals the process group of the session, which Setsid made the
+// child the leader of. Cancelling ctx cuts the remaining grace periods short.
+func (s *unixSession) Terminate(ctx context.Context, p TerminatePolicy) (TerminateStep, error) {
+	go s.Wait()
+	select {
+	case <-s.done:
+		return TerminateNone, nil
+	default:
+	}
+
+	steps := []struct {
+		step  TerminateStep
+		sig   unix.Signal
+		grace time.Duration
+	}{
+		{TerminateHangup, unix.SIGHUP, p.HangupGrace},
+		{TerminateTerm, unix.SIGTERM, p.TermGrace},
+	}
+	for _, st := range steps {
+		if st.grace <= 0 {
+			continue
+		}
+		if err := s.signalGroup(st.sig); err != nil {
+			return st.step, err
+		}
+		if s.waitExit(ctx, st.grace) {
+			return st.step, nil
+		}
+	}
+	kill := func() error { return s.signalGroup(unix.SIGKILL) }
+	if s.killTree {
+		kill = s.killProcessTree
+	}
+	if err := kill(); err != nil {
+		return TerminateKill, err
+	}
+	<-s.done
+	return TerminateKill, nil
+}
+
+func (s *unixSession) signalGroup(sig unix.Signal) error {
+	if err := unix.Kill(-s.cmd.Process.Pid, sig); err != nil && !errors.Is(err, unix.ESRCH) {
+		return err
+	}
+	return nil
+}
+
+// exited reports whether Wait has reaped the child. Its pid and session id
+// may belong to other processes from then on.
+func (s *unixSession) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// killProcessTree kills the process group and every other process found in
+// the child's session or below it, which catches background jobs that a
+// shell moved to their own process group.
+func (s *unixSession) killProcessTree() error {
+	pids := processTree(s.cmd.Process.Pid)
+	err := s.signalGroup(unix.SIGKILL)
+	for _, pid := range pids {
+		_ = unix.Kill(pid, unix.SIGKILL)
+	}
+	if s.subreaper {
+		reap(pids)
+	}
+	return err
+}
+
+// killLeftover kills what was left of the process tree when the child
+// exited. The child's pid and group may belong to other processes by then,
+// so only the processes found before it was reaped are killed, once.
+func (s *unixSession) killLeftover() {
+	s.leftMu.Lock()
+	pids := s.leftover
+	s.leftover = nil
+	s.leftMu.Unlock()
+	for _, pid := range pids {
+		_ = unix.Kill(pid, unix.SIGKILL)
+	}
+	if s.subreaper {
+		reap(pids)
+	}
+}
+
+func (s *unixSession) waitExit(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-s.done:
+		return true
+	case <-t.C:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+func (s *unixSession) foregroundPgrp() (int, error) {
+	return unix.IoctlGetInt(int(s.master.Fd()), unix.TIOCGPGRP)
+}
+
+func newExitError(exitErr *exec.ExitError) *ExitError {
+	e := &ExitError{ExitCode: exitErr.ExitCode(), waitStatus: exitErr.Sys()}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+		e.Exited = ws.Exited()
+		if ws.Signaled() {
+			e.Signal = ws.Signal()
+			e.CoreDumped = ws.CoreDump()
+		}
+	}
+	return e
+}
+
+func setWinsize(fd int, cols, rows int) error { return setWinsizePixels(fd, cols, rows, 0, 0) }
+
+func setWinsizePixels(fd int, cols, rows, xpixel, ypixel int) error {
+	ws := &unix.Winsize{Col: uint16(cols), Row: uint16(rows), Xpixel: uint16(xpixel), Ypixel: uint16(ypixel)}
 	return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, ws)
 }
 
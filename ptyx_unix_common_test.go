@@ -15,6 +15,9 @@ import (
 	"syscall"
 	"testing"
 	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
 )
 
 func TestHelperProcess(t *testing.T) {
@@ -27,6 +30,33 @@ func TestHelperProcess(t *testing.T) {
 	case "dir":
 		wd, _ := os.Getwd()
 		fmt.Println(wd)
+	case "ttyconsole":
+		c, err := NewTTYConsole()
+		if err != nil {
+			fmt.Println("error:", err)
+			break
+		}
+		w, h := c.Size()
+		fmt.Printf("tty %dx%d\n", w, h)
+		_ = c.Close()
+	case "keys":
+		st, err := term.MakeRaw(0)
+		if err != nil {
+			fmt.Println("error:", err)
+			break
+		}
+		defer term.Restore(0, st)
+		fmt.Print("\x1b[?1h\x1b=\x1b[?2004hready\n")
+		var in []byte
+		buf := make([]byte, 64)
+		for !bytes.HasSuffix(in, pasteEnd) {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				break
+			}
+			in = append(in, buf[:n]...)
+		}
+		fmt.Printf("%q\n", in)
 	default:
 		fmt.Println("noop")
 	}
@@ -162,6 +192,52 @@ func TestUnixSession_Wait_ExitError(t *testing.T) {
 	if !errors.As(waitErr, &exitErr) || exitErr.ExitCode != 17 {
 		t.Fatalf("Wait() error = %v (type %T), want *ptyx.ExitError with code 17", waitErr, waitErr)
 	}
+	if !exitErr.Exited || exitErr.Signal != nil || exitErr.Canceled || exitErr.Success() {
+		t.Errorf("ExitError = %+v, want a normal non-zero exit", exitErr)
+	}
+}
+
+func TestUnixSession_Wait_Signaled(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "sh", Args: []string{"-c", "kill -SEGV $$"}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	go io.Copy(io.Discard, s.PtyReader())
+
+	var exitErr *ExitError
+	if err := s.Wait(); !errors.As(err, &exitErr) {
+		t.Fatalf("Wait() error = %v, want *ptyx.ExitError", err)
+	}
+	if exitErr.Exited || exitErr.Signal != syscall.SIGSEGV || exitErr.Canceled || exitErr.ExitCode != -1 {
+		t.Errorf("ExitError = %+v, want killed by SIGSEGV", exitErr)
+	}
+}
+
+func TestUnixSession_Wait_Canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s, err := Spawn(ctx, SpawnOpts{Prog: "sleep", Args: []string{"30"}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sleep', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	go io.Copy(io.Discard, s.PtyReader())
+
+	err = s.Wait()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || !exitErr.Canceled || exitErr.Signal != syscall.SIGKILL {
+		t.Fatalf("Wait() error = %#v, want a canceled *ptyx.ExitError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want it to wrap context.DeadlineExceeded", err)
+	}
 }
 
 func TestUnixSession_Kill(t *testing.T) {
@@ -207,6 +283,388 @@ func TestUnixSession_Kill(t *testing.T) {
 	}
 }
 
+func TestUnixSession_Signal(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "sleep", Args: []string{"30"}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sleep', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	go io.Copy(io.Discard, s.PtyReader())
+
+	if err := s.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("Signal() failed: %v", err)
+	}
+	var exitErr *ExitError
+	if err := s.Wait(); !errors.As(err, &exitErr) {
+		t.Fatalf("Wait() error = %v, want *ptyx.ExitError", err)
+	}
+}
+
+func TestUnixSession_SignalForeground(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "sh", Args: []string{"-c", "trap 'echo trapped' INT; set -m; sleep 30; echo after $?"}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+
+	us := s.(*unixSession)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pgrp, err := us.foregroundPgrp()
+		if err != nil {
+			t.Fatalf("foregroundPgrp() failed: %v", err)
+		}
+		if pgrp != s.Pid() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Skip("shell did not put the job in the foreground")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := s.SignalForeground(os.Interrupt); err != nil {
+		t.Fatalf("SignalForeground() failed: %v", err)
+	}
+
+	out := make(chan string, 1)
+	go func() {
+		b, _ := io.ReadAll(s.PtyReader())
+		out <- string(b)
+	}()
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait() = %v, want the shell to survive its job being interrupted", err)
+	}
+	// Closing the master now would cut off output not read yet; the read
+	// ends with EIO on its own once the shell has exited.
+	var got string
+	select {
+	case got = <-out:
+	case <-time.After(5 * time.Second):
+		t.Fatal("output did not end after the shell exited")
+	}
+	if !strings.Contains(got, "after 130") {
+		t.Errorf("output = %q, want it to contain %q", got, "after 130")
+	}
+}
+
+func TestUnixSession_Terminate(t *testing.T) {
+	policy := TerminatePolicy{HangupGrace: 2 * time.Second, TermGrace: 2 * time.Second}
+	tests := []struct {
+		name   string
+		script string
+		policy TerminatePolicy
+		want   TerminateStep
+	}{
+		{"Hangup", "echo ready; sleep 30", policy, TerminateHangup},
+		{"Term", `trap "" HUP; echo ready; sleep 30`, policy, TerminateTerm},
+		{"Kill", `trap "" HUP TERM; echo ready; sleep 30`, TerminatePolicy{HangupGrace: 100 * time.Millisecond, TermGrace: 100 * time.Millisecond}, TerminateKill},
+		{"SkipToKill", "echo ready; sleep 30", TerminatePolicy{}, TerminateKill},
+		{"AlreadyExited", "echo ready", policy, TerminateNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Spawn(context.Background(), SpawnOpts{Prog: "sh", Args: []string{"-c", tt.script}})
+			if err != nil {
+				if errors.Is(err, exec.ErrNotFound) {
+					t.Skipf("could not find 'sh', skipping test: %v", err)
+				}
+				t.Fatalf("Spawn failed: %v", err)
+			}
+			defer s.Close()
+
+			if _, err := readPTYOneLine(s.PtyReader()); err != nil {
+				t.Fatalf("reading ready line failed: %v", err)
+			}
+			go io.Copy(io.Discard, s.PtyReader())
+			if tt.want == TerminateNone {
+				_ = s.Wait()
+			}
+
+			start := time.Now()
+			step, err := s.Terminate(context.Background(), tt.policy)
+			if err != nil {
+				t.Fatalf("Terminate() failed: %v", err)
+			}
+			if step != tt.want {
+				t.Errorf("Terminate() step = %v, want %v", step, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("Terminate() took %v", elapsed)
+			}
+			_ = s.Wait()
+		})
+	}
+}
+
+func TestUnixSession_Terminate_ContextCancel(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "sh", Args: []string{"-c", `trap "" HUP TERM; echo ready; sleep 30`}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	if _, err := readPTYOneLine(s.PtyReader()); err != nil {
+		t.Fatalf("reading ready line failed: %v", err)
+	}
+	go io.Copy(io.Discard, s.PtyReader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	step, err := s.Terminate(ctx, TerminatePolicy{HangupGrace: time.Minute, TermGrace: time.Minute})
+	if err != nil || step != TerminateKill {
+		t.Fatalf("Terminate() = %v, %v, want %v", step, err, TerminateKill)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Terminate() took %v, context cancellation was not honoured", elapsed)
+	}
+}
+
+func TestUnixSession_SendEOF(t *testing.T) {
+	custom := DefaultTermios
+	custom.EOF = 0x01
+	tests := []struct {
+		name    string
+		termios *Termios
+	}{
+		{"Default", nil},
+		{"RemappedVEOF", &custom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Spawn(context.Background(), SpawnOpts{Prog: "cat", Termios: tt.termios})
+			if err != nil {
+				if errors.Is(err, exec.ErrNotFound) {
+					t.Skipf("could not find 'cat', skipping test: %v", err)
+				}
+				t.Fatalf("Spawn failed: %v", err)
+			}
+			defer s.Close()
+
+			out := make(chan string, 1)
+			go func() {
+				b, _ := io.ReadAll(s.PtyReader())
+				out <- string(b)
+			}()
+
+			if _, err := io.WriteString(s.PtyWriter(), "hello\n"); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			if err := s.SendEOF(); err != nil {
+				t.Fatalf("SendEOF() failed: %v", err)
+			}
+
+			waitCh := make(chan error, 1)
+			go func() { waitCh <- s.Wait() }()
+			select {
+			case err := <-waitCh:
+				if err != nil {
+					t.Fatalf("Wait() = %v, want cat to exit cleanly on EOF", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("cat did not exit after SendEOF()")
+			}
+
+			// Echo plus cat's own copy; both must survive the half-close.
+			if got := <-out; strings.Count(got, "hello") != 2 {
+				t.Errorf("output = %q, want the echoed and copied line", got)
+			}
+		})
+	}
+}
+
+func TestUnixSession_ResizeWithPixels(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "sleep", Args: []string{"30"}, Cols: 80, Rows: 24, XPixel: 640, YPixel: 384})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sleep', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	fd := int(s.(*unixSession).master.Fd())
+
+	check := func(step string, want unix.Winsize) {
+		t.Helper()
+		ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+		if err != nil {
+			t.Fatalf("%s: TIOCGWINSZ failed: %v", step, err)
+		}
+		if *ws != want {
+			t.Errorf("%s: winsize = %+v, want %+v", step, *ws, want)
+		}
+	}
+
+	check("Spawn", unix.Winsize{Col: 80, Row: 24, Xpixel: 640, Ypixel: 384})
+	if err := s.ResizeWithPixels(100, 30, 1000, 600); err != nil {
+		t.Fatalf("ResizeWithPixels() failed: %v", err)
+	}
+	check("ResizeWithPixels", unix.Winsize{Col: 100, Row: 30, Xpixel: 1000, Ypixel: 600})
+	if err := s.Resize(90, 20); err != nil {
+		t.Fatalf("Resize() failed: %v", err)
+	}
+	check("Resize", unix.Winsize{Col: 90, Row: 20})
+}
+
+func TestUnixSession_PtyReaderIsFile(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "true"})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'true', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	// Without TrackKeyModes the PTY itself is returned, deadlines and all.
+	if _, ok := s.PtyReader().(*os.File); !ok {
+		t.Errorf("PtyReader() = %T, want *os.File", s.PtyReader())
+	}
+	go io.Copy(io.Discard, s.PtyReader())
+	_ = s.Wait()
+}
+
+func TestUnixSession_SendKeys(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := Spawn(ctx, SpawnOpts{
+		Prog: os.Args[0],
+		Args: []string{"-test.run=TestHelperProcess"},
+		Env:  append(os.Environ(), "PTYX_HELPER=1", "MODE=keys"),
+
+		TrackKeyModes: true,
+	})
+	if err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+
+	br := bufio.NewReader(s.PtyReader())
+	line, err := br.ReadString('\n')
+	if err != nil || !strings.HasSuffix(line, "ready\n") {
+		t.Fatalf("read %q, %v; want ready", line, err)
+	}
+	if got, want := s.KeyModes(), (KeyModes{AppCursor: true, AppKeypad: true, BracketedPaste: true}); got != want {
+		t.Errorf("KeyModes() = %+v, want %+v", got, want)
+	}
+
+	if err := s.SendKeys(KeyUp, KeyCtrlC, KeyF5, "hi"); err != nil {
+		t.Fatalf("SendKeys() failed: %v", err)
+	}
+	line, err = br.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading echoed input failed: %v", err)
+	}
+	if got, want := strings.TrimRight(line, "\r\n"), `"\x1bOA\x03\x1b[15~\x1b[200~hi\x1b[201~"`; got != want {
+		t.Errorf("child read %s, want %s", got, want)
+	}
+	_ = s.Wait()
+}
+
+func TestStartCmd(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() failed: %v", err)
+	}
+	defer r.Close()
+	_, _ = io.WriteString(w, "extra-fd")
+	w.Close()
+
+	cmd := exec.Command("sh", "-c", `echo "$PTYX_CMD_VAR"; cat <&3; echo; [ -t 1 ] && echo tty`)
+	cmd.Env = append(os.Environ(), "PTYX_CMD_VAR=from-cmd")
+	cmd.ExtraFiles = []*os.File{r}
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+	s, err := StartCmd(cmd, SpawnOpts{Cols: 100, Rows: 30})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh', skipping test: %v", err)
+		}
+		t.Fatalf("StartCmd failed: %v", err)
+	}
+	defer s.Close()
+
+	if !cmd.SysProcAttr.Setsid || !cmd.SysProcAttr.Setctty {
+		t.Errorf("SysProcAttr = %+v, want Setsid and Setctty", cmd.SysProcAttr)
+	}
+	out, _ := io.ReadAll(s.PtyReader())
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	for _, want := range []string{"from-cmd", "extra-fd", "tty"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output = %q, want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestStartCmd_ExtraFilesNotShared(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// With every stream off the PTY the slave is appended to ExtraFiles,
+	// which must not land in the spare capacity of the caller's slice.
+	extra := make([]*os.File, 1, 2)
+	extra[0] = r
+	var out bytes.Buffer
+	cmd := exec.Command("true")
+	cmd.ExtraFiles = extra
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(""), &out, &out
+	s, err := StartCmd(cmd, SpawnOpts{})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'true', skipping test: %v", err)
+		}
+		t.Fatalf("StartCmd failed: %v", err)
+	}
+	defer s.Close()
+	go io.Copy(io.Discard, s.PtyReader())
+	_ = s.Wait()
+
+	if got := extra[:2][1]; got != nil {
+		t.Errorf("StartCmd wrote %v past the end of the caller's ExtraFiles", got.Name())
+	}
+}
+
+func TestStartCmd_Errors(t *testing.T) {
+	t.Run("ConflictingSysProcAttr", func(t *testing.T) {
+		cmd := exec.Command("true")
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		if _, err := StartCmd(cmd, SpawnOpts{}); err == nil {
+			t.Fatal("StartCmd() with Setpgid should have failed")
+		}
+	})
+
+	t.Run("AlreadyStarted", func(t *testing.T) {
+		cmd := exec.Command("true")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("could not start 'true': %v", err)
+		}
+		_ = cmd.Wait()
+		if _, err := StartCmd(cmd, SpawnOpts{}); err == nil {
+			t.Fatal("StartCmd() on a started command should have failed")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		cmd := exec.Command("a-program-that-does-not-exist-12345")
+		if _, err := StartCmd(cmd, SpawnOpts{}); !errors.Is(err, exec.ErrNotFound) {
+			t.Fatalf("StartCmd() error = %v, want exec.ErrNotFound", err)
+		}
+	})
+}
+
 func spawnReadOneLineAndClose(ctx context.Context, opts SpawnOpts, timeout time.Duration) (string, error) {
 	s, err := Spawn(ctx, opts)
 	if err != nil {
@@ -239,8 +697,15 @@ func spawnReadOneLineAndClose(ctx context.Context, opts SpawnOpts, timeout time.
 		}
 		line = lr.line
 	case werr := <-waitCh:
+		// The slave is gone once the child exits, so the read ends with EIO;
+		// closing the master first would race it.
+		var lr lineRes
+		select {
+		case lr = <-lineCh:
+		case <-timer.C:
+			lr.err = fmt.Errorf("timeout reading line")
+		}
 		_ = s.Close()
-		lr := <-lineCh
 		if lr.err != nil && !isPTYEOF(lr.err) && !errors.Is(lr.err, io.EOF) {
 			return "", lr.err
 		}
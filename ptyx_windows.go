@@ -4,6 +4,7 @@ package ptyx
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unicode/utf16"
 	"unsafe"
 
@@ -18,6 +20,7 @@ import (
 )
 
 type winSession struct {
+	ctx      context.Context
 	con      *ConPty
 	pid      int
 	process  windows.Handle
@@ -26,6 +29,8 @@ type winSession struct {
 	killed   uint32
 	closeOnce sync.Once
 	conOnce   sync.Once
+	trackKeys bool
+	keys      keyModeTracker
 }
 
 func buildCommandLine(prog string, args []string) string {
@@ -56,7 +61,12 @@ func buildEnvBlock(env []string) []uint16 {
 	return utf16.Encode([]rune(blockStr))
 }
 
+// Spawn always kills the whole process tree through a job object, so
+// SpawnOpts.KillProcessTree has no extra effect here.
 func Spawn(ctx context.Context, opts SpawnOpts) (Session, error) {
+	if opts.Subreaper || opts.Termios != nil || opts.PtmxPath != "" || !allStreamsOnPTY(opts) {
+		return nil, ErrUnsupported
+	}
 	con, err := NewConPty(opts.Cols, opts.Rows, 0)
 	if err != nil {
 		return nil, err
@@ -164,11 +174,14 @@ func Spawn(ctx context.Context, opts SpawnOpts) (Session, error) {
 	}
 
 	sess := &winSession{
+		ctx:     ctx,
 		con:     con,
 		pid:     int(pi.ProcessId),
 		process: pi.Process,
 		thread:  pi.Thread,
 		job:     job,
+
+		trackKeys: opts.TrackKeyModes,
 	}
 
 	closeCon := func() {
@@ -179,8 +192,7 @@ func Spawn(ctx context.Context, opts SpawnOpts) (Session, error) {
 		})
 	}
 
-	go func() {
-		<-ctx.Done()
+	stopKill := context.AfterFunc(ctx, func() {
 		atomic.StoreUint32(&sess.killed, 1)
 		if sess.job != 0 {
 			windows.CloseHandle(sess.job)
@@ -191,19 +203,32 @@ func Spawn(ctx context.Context, opts SpawnOpts) (Session, error) {
 		if st == uint32(windows.WAIT_TIMEOUT) {
 			closeCon()
 		}
-	}()
+	})
 
 	go func() {
 		_, _ = windows.WaitForSingleObject(pi.Process, windows.INFINITE)
+		stopKill()
 		closeCon()
 	}()
 
 	return sess, nil
 }
 
-func (s *winSession) PtyReader() io.Reader        { return s.con.outFile }
+func (s *winSession) PtyReader() io.Reader {
+	if s.trackKeys {
+		return io.TeeReader(s.con.outFile, &s.keys)
+	}
+	return s.con.outFile
+}
 func (s *winSession) PtyWriter() io.Writer        { return s.con.inFile }
+func (s *winSession) KeyModes() KeyModes          { return s.keys.get() }
+func (s *winSession) SendKeys(keys ...any) error  { return s.keys.sendKeys(s.con.inFile, keys) }
 func (s *winSession) Resize(cols, rows int) error { return s.con.resize(cols, rows) }
+
+// ResizeWithPixels resizes in cells only; ConPTY has no pixel size.
+func (s *winSession) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	return s.con.resize(cols, rows)
+}
 func (s *winSession) Pid() int                    { return s.pid }
 
 func (s *winSession) Wait() error {
@@ -219,15 +244,27 @@ func (s *winSession) Wait() error {
 		return err
 	}
 	if atomic.LoadUint32(&s.killed) == 1 {
-		if code == 0 { return &ExitError{ExitCode: 1, waitStatus: nil} }
-		return &ExitError{ExitCode: int(code), waitStatus: nil}
+		if code == 0 {
+			code = 1
+		}
+		e := &ExitError{ExitCode: int(code), Signal: os.Kill}
+		if s.ctx != nil && s.ctx.Err() != nil {
+			return canceledError(e, s.ctx.Err())
+		}
+		return e
 	}
 	if code == 0 {
 		return nil
 	}
-	return &ExitError{ExitCode: int(code), waitStatus: nil}
+	if code == statusControlCExit {
+		return &ExitError{ExitCode: int(code), Signal: os.Interrupt}
+	}
+	return &ExitError{ExitCode: int(code), Exited: true}
 }
 
+// statusControlCExit is the exit code of a process ended by Ctrl+C.
+const statusControlCExit = 0xC000013A
+
 func (s *winSession) Kill() error {
 	atomic.StoreUint32(&s.killed, 1)
 	if s.job != 0 {
@@ -278,3 +315,77 @@ func (s *winSession) CloseStdin() error {
 	}
 	return s.con.inFile.Close()
 }
+
+// Signal maps os.Interrupt to a ^C on the console input, which ConPTY turns
+// into a CTRL_C_EVENT for the attached processes, and os.Kill to Kill.
+func (s *winSession) Signal(sig os.Signal) error {
+	switch sig {
+	case os.Interrupt:
+		_, err := s.con.inFile.Write([]byte{0x03})
+		return err
+	case os.Kill:
+		return s.Kill()
+	}
+	return ErrUnsupported
+}
+
+func (s *winSession) SignalForeground(sig os.Signal) error { return s.Signal(sig) }
+
+// Terminate has no SIGHUP or SIGTERM to send on Windows; if either grace
+// period is set it sends a single ^C and waits for their sum, then kills.
+func (s *winSession) Terminate(ctx context.Context, p TerminatePolicy) (TerminateStep, error) {
+	if st, _ := windows.WaitForSingleObject(s.process, 0); st == windows.WAIT_OBJECT_0 {
+		return TerminateNone, nil
+	}
+	if grace := p.HangupGrace + p.TermGrace; grace > 0 {
+		if err := s.Signal(os.Interrupt); err != nil {
+			return TerminateTerm, err
+		}
+		deadline := time.Now().Add(grace)
+		for time.Now().Before(deadline) && ctx.Err() == nil {
+			if st, _ := windows.WaitForSingleObject(s.process, 50); st == windows.WAIT_OBJECT_0 {
+				return TerminateTerm, nil
+			}
+		}
+	}
+	return TerminateKill, s.Kill()
+}
+
+// ConPTY has no line discipline to inspect or change.
+func (s *winSession) Termios() (Termios, error) { return Termios{}, ErrUnsupported }
+func (s *winSession) SetTermios(t Termios) error  { return ErrUnsupported }
+
+// SendEOF closes the ConPTY input pipe; output stays readable until the
+// process exits.
+func (s *winSession) SendEOF() error { return s.CloseStdin() }
+
+// ConPTY owns all three standard streams, so there are never extra pipes.
+func (s *winSession) StdinPipe() io.WriteCloser { return nil }
+func (s *winSession) StdoutPipe() io.ReadCloser { return nil }
+func (s *winSession) StderrPipe() io.ReadCloser { return nil }
+
+func allStreamsOnPTY(opts SpawnOpts) bool {
+	return opts.StdinMode == StreamPTY && opts.StdoutMode == StreamPTY && opts.StderrMode == StreamPTY &&
+		opts.Stdin == nil && opts.Stdout == nil && opts.Stderr == nil
+}
+
+// StartCmd runs cmd's Path, Args, Env and Dir on a new ConPTY. Fields that
+// cannot be honoured there, such as SysProcAttr, ExtraFiles and standard
+// streams, make it return ErrUnsupported.
+func StartCmd(cmd *exec.Cmd, opts SpawnOpts) (Session, error) {
+	if cmd.Process != nil {
+		return nil, errors.New("ptyx: exec: already started")
+	}
+	if cmd.Err != nil {
+		return nil, cmd.Err
+	}
+	if cmd.SysProcAttr != nil || len(cmd.ExtraFiles) > 0 || cmd.Stdin != nil || cmd.Stdout != nil || cmd.Stderr != nil {
+		return nil, ErrUnsupported
+	}
+	opts.Prog, opts.Env, opts.Dir = cmd.Path, cmd.Env, cmd.Dir
+	opts.Args = nil
+	if len(cmd.Args) > 1 {
+		opts.Args = cmd.Args[1:]
+	}
+	return Spawn(context.Background(), opts)
+}
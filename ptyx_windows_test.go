@@ -13,6 +13,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 	"unicode/utf16"
@@ -144,11 +145,56 @@ func TestWinSession_Kill(t *testing.T) {
 		if !errors.As(waitErr, &exitErr) || exitErr.ExitCode != 1 {
 			t.Fatalf("Wait() error = %v (type %T), want *ptyx.ExitError with code 1", waitErr, waitErr)
 		}
+		if exitErr.Exited || exitErr.Signal != os.Kill || exitErr.Canceled {
+			t.Errorf("ExitError = %+v, want killed by os.Kill", exitErr)
+		}
 	case <-time.After(5 * time.Second):
 		t.Fatal("s.Wait() timed out after s.Kill()")
 	}
 }
 
+func TestWinSession_Signal_Unsupported(t *testing.T) {
+	s := &winSession{}
+	if err := s.Signal(syscall.SIGTERM); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Signal(SIGTERM) = %v, want ErrUnsupported", err)
+	}
+	if err := s.SignalForeground(syscall.SIGHUP); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SignalForeground(SIGHUP) = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestWinSession_Termios_Unsupported(t *testing.T) {
+	s := &winSession{}
+	if _, err := s.Termios(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Termios() = %v, want ErrUnsupported", err)
+	}
+	if err := s.SetTermios(DefaultTermios); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SetTermios() = %v, want ErrUnsupported", err)
+	}
+	if _, err := Spawn(context.Background(), SpawnOpts{Prog: "cmd", Termios: &DefaultTermios}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Spawn() with Termios = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestStartCmd_Unsupported(t *testing.T) {
+	cmd := exec.Command("cmd", "/c", "exit")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if _, err := StartCmd(cmd, SpawnOpts{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("StartCmd() with SysProcAttr = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestWindowsSpawn_StreamModes_Unsupported(t *testing.T) {
+	for _, opts := range []SpawnOpts{
+		{Prog: "cmd", StderrMode: StreamPipe},
+		{Prog: "cmd", Stdout: io.Discard},
+	} {
+		if _, err := Spawn(context.Background(), opts); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Spawn(%+v) = %v, want ErrUnsupported", opts, err)
+		}
+	}
+}
+
 func TestWindowsSpawn_WithOptions(t *testing.T) {
 	t.Run("Env", func(t *testing.T) {
 		line, err := spawnReadOneLineAndCloseWin(context.Background(), SpawnOpts{
@@ -0,0 +1,261 @@
+package ptyx
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx/vtparse"
+)
+
+// ErrQueryTimeout is returned when the terminal does not answer a query in
+// time.
+var ErrQueryTimeout = errors.New("ptyx: terminal did not answer in time")
+
+// ErrNoReply is returned when the terminal answered the DA1 request sent
+// after a query but not the query itself, so it does not support it.
+var ErrNoReply = errors.New("ptyx: terminal ignored the query")
+
+// PrimaryAttributes is the reply to DA1. Level is the conformance level, for
+// example 62 for VT220; Features lists the supported extensions.
+type PrimaryAttributes struct {
+	Level    int
+	Features []int
+}
+
+// SecondaryAttributes is the reply to DA2.
+type SecondaryAttributes struct {
+	Type    int
+	Version int
+	ROM     int
+}
+
+// ModeState is a DECRQM reply.
+type ModeState int
+
+const (
+	ModeNotRecognized ModeState = iota
+	ModeSet
+	ModeReset
+	ModePermanentlySet
+	ModePermanentlyReset
+)
+
+func (s ModeState) String() string {
+	switch s {
+	case ModeNotRecognized:
+		return "not recognized"
+	case ModeSet:
+		return "set"
+	case ModeReset:
+		return "reset"
+	case ModePermanentlySet:
+		return "permanently set"
+	case ModePermanentlyReset:
+		return "permanently reset"
+	}
+	return fmt.Sprintf("ModeState(%d)", int(s))
+}
+
+// IsSet reports whether the mode is set, permanently or not.
+func (s ModeState) IsSet() bool { return s == ModeSet || s == ModePermanentlySet }
+
+const requestDA1 = "\x1b[c"
+
+func (c *console) PrimaryDeviceAttributes(timeout time.Duration) (PrimaryAttributes, error) {
+	var a PrimaryAttributes
+	err := c.query(requestDA1, timeout, func(e vtparse.Event) bool {
+		if !isDA1(e) {
+			return false
+		}
+		a.Level = e.Params.Get(0, 0)
+		for i := 1; i < e.Params.Len(); i++ {
+			a.Features = append(a.Features, e.Params.Get(i, 0))
+		}
+		return true
+	})
+	return a, err
+}
+
+func (c *console) SecondaryDeviceAttributes(timeout time.Duration) (SecondaryAttributes, error) {
+	var a SecondaryAttributes
+	err := c.query("\x1b[>c", timeout, func(e vtparse.Event) bool {
+		if e.Kind != vtparse.KindCSI || e.Private != '>' || e.Final != 'c' {
+			return false
+		}
+		a = SecondaryAttributes{Type: e.Params.Get(0, 0), Version: e.Params.Get(1, 0), ROM: e.Params.Get(2, 0)}
+		return true
+	})
+	return a, err
+}
+
+// TerminalVersion asks for the terminal's name and version (XTVERSION), for
+// example "xterm(388)".
+func (c *console) TerminalVersion(timeout time.Duration) (string, error) {
+	var v string
+	err := c.query("\x1b[>0q", timeout, func(e vtparse.Event) bool {
+		if e.Kind != vtparse.KindDCS || e.Private != '>' || e.Final != '|' {
+			return false
+		}
+		v = string(e.Data)
+		return true
+	})
+	return v, err
+}
+
+// ModeStatus asks whether a DEC private mode, such as ModeBracketedPaste, is
+// set (DECRQM).
+func (c *console) ModeStatus(mode int, timeout time.Duration) (ModeState, error) {
+	var s ModeState
+	err := c.query(CSI("?"+strconv.Itoa(mode)+"$p"), timeout, func(e vtparse.Event) bool {
+		if e.Kind != vtparse.KindCSI || e.Private != '?' || e.Final != 'y' ||
+			string(e.Intermediates) != "$" || e.Params.Get(0, -1) != mode {
+			return false
+		}
+		s = ModeState(e.Params.Get(1, 0))
+		return true
+	})
+	return s, err
+}
+
+func (c *console) ForegroundColor(timeout time.Duration) (color.RGBA, error) {
+	return c.queryColor(10, timeout)
+}
+
+func (c *console) BackgroundColor(timeout time.Duration) (color.RGBA, error) {
+	return c.queryColor(11, timeout)
+}
+
+func (c *console) queryColor(code int, timeout time.Duration) (color.RGBA, error) {
+	var rgb color.RGBA
+	var perr error
+	prefix := strconv.Itoa(code) + ";"
+	err := c.query(OSC(prefix+"?"), timeout, func(e vtparse.Event) bool {
+		if e.Kind != vtparse.KindOSC || !strings.HasPrefix(string(e.Data), prefix) {
+			return false
+		}
+		rgb, perr = parseColorSpec(string(e.Data[len(prefix):]))
+		return true
+	})
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return rgb, perr
+}
+
+// CursorPosition asks for the cursor position (DSR 6), 1-based like CUP.
+func (c *console) CursorPosition(timeout time.Duration) (row, col int, err error) {
+	err = c.query(CSI("6n"), timeout, func(e vtparse.Event) bool {
+		if e.Kind != vtparse.KindCSI || e.Private != 0 || e.Final != 'R' || e.Params.Len() != 2 {
+			return false
+		}
+		row, col = e.Params.Get(0, 1), e.Params.Get(1, 1)
+		return true
+	})
+	return row, col, err
+}
+
+func isDA1(e vtparse.Event) bool {
+	return e.Kind == vtparse.KindCSI && e.Private == '?' && e.Final == 'c' && len(e.Intermediates) == 0
+}
+
+// query writes req and reads replies until match accepts one. Every request
+// except DA1 itself is followed by a DA1 request, which all terminals answer:
+// a DA1 reply before the expected one means the terminal ignored req, and
+// ErrNoReply is returned without waiting for the timeout. Other input read
+// while waiting is discarded, so queries must not run while a Mux is reading
+// the console.
+//
+// A DA1 reply that arrives after its query timed out is skipped by the next
+// query, but until then it stays in the input for any other reader.
+func (c *console) query(req string, timeout time.Duration, match func(vtparse.Event) bool) error {
+	if c.in == nil || c.out == nil || !c.outTTY {
+		return ErrNotAConsole
+	}
+	c.queryMu.Lock()
+	defer c.queryMu.Unlock()
+
+	if c.raw == nil {
+		st, err := c.MakeRaw()
+		if err != nil {
+			return err
+		}
+		defer c.restoreRaw(st)
+	}
+
+	sentinel := req != requestDA1
+	if sentinel {
+		req += requestDA1
+	}
+	if _, err := c.out.WriteString(req); err != nil {
+		return err
+	}
+
+	var p vtparse.Parser
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(timeout)
+	matched, done := false, false
+	var result error
+	for !done {
+		left := time.Until(deadline)
+		if left <= 0 {
+			break
+		}
+		n, err := c.readTimeout(buf, left)
+		if errors.Is(err, ErrQueryTimeout) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		p.Parse(buf[:n], func(e vtparse.Event) {
+			switch {
+			case done:
+			case isDA1(e) && c.pendingDA1 > 0:
+				c.pendingDA1--
+			case !matched && match(e):
+				matched = true
+				done = !sentinel
+			case sentinel && isDA1(e):
+				if !matched {
+					result = ErrNoReply
+				}
+				done = true
+			}
+		})
+	}
+	if !done {
+		// The DA1 request is still unanswered; its reply may come later.
+		c.pendingDA1++
+	}
+	if matched {
+		return nil
+	}
+	if result != nil {
+		return result
+	}
+	return ErrQueryTimeout
+}
+
+// parseColorSpec parses an X11 "rgb:r/g/b" color of 1 to 4 hex digits per
+// component, as sent in OSC 10 and 11 replies.
+func parseColorSpec(s string) (color.RGBA, error) {
+	spec, ok := strings.CutPrefix(s, "rgb:")
+	parts := strings.Split(spec, "/")
+	if !ok || len(parts) != 3 {
+		return color.RGBA{}, fmt.Errorf("ptyx: unsupported color %q", s)
+	}
+	var v [3]uint8
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 16, 16)
+		if err != nil || len(part) == 0 || len(part) > 4 {
+			return color.RGBA{}, fmt.Errorf("ptyx: unsupported color %q", s)
+		}
+		max := uint64(1)<<(4*len(part)) - 1
+		v[i] = uint8((n*255 + max/2) / max)
+	}
+	return color.RGBA{R: v[0], G: v[1], B: v[2], A: 0xff}, nil
+}
@@ -0,0 +1,56 @@
+package ptyx
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseColorSpec(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"rgb:ffff/ffff/ffff", color.RGBA{255, 255, 255, 255}, false},
+		{"rgb:0000/8080/ffff", color.RGBA{0, 128, 255, 255}, false},
+		{"rgb:1e/1e/2e", color.RGBA{0x1e, 0x1e, 0x2e, 255}, false},
+		{"rgb:f/8/0", color.RGBA{255, 136, 0, 255}, false},
+		{"rgb:fff/000/800", color.RGBA{255, 0, 128, 255}, false},
+		{"#ffffff", color.RGBA{}, true},
+		{"rgb:ff/ff", color.RGBA{}, true},
+		{"rgb:ff//ff", color.RGBA{}, true},
+		{"rgb:fffff/0/0", color.RGBA{}, true},
+		{"rgb:zz/0/0", color.RGBA{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseColorSpec(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseColorSpec(%q) = %v, %v, want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModeState(t *testing.T) {
+	tests := []struct {
+		s     ModeState
+		str   string
+		isSet bool
+	}{
+		{ModeNotRecognized, "not recognized", false},
+		{ModeSet, "set", true},
+		{ModeReset, "reset", false},
+		{ModePermanentlySet, "permanently set", true},
+		{ModePermanentlyReset, "permanently reset", false},
+		{ModeState(9), "ModeState(9)", false},
+	}
+	for _, tt := range tests {
+		if got := tt.s.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+		if got := tt.s.IsSet(); got != tt.isSet {
+			t.Errorf("%v.IsSet() = %v, want %v", tt.s, got, tt.isSet)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package ptyx
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx/vtparse"
+)
+
+// fakeTerminal answers queries written to the slave side of a PTY the way a
+// terminal emulator would. Requests without an entry in replies are ignored,
+// except DA1, which is always answered unless silent is set.
+type fakeTerminal struct {
+	master  *os.File
+	replies map[string]string
+	silent  bool
+	noise   string
+}
+
+func (ft *fakeTerminal) serve() {
+	var p vtparse.Parser
+	buf := make([]byte, 256)
+	for {
+		n, err := ft.master.Read(buf)
+		if err != nil {
+			return
+		}
+		var out strings.Builder
+		p.Parse(buf[:n], func(e vtparse.Event) {
+			if e.Kind != vtparse.KindCSI && e.Kind != vtparse.KindOSC {
+				return
+			}
+			req := e.Sequence()
+			if r, ok := ft.replies[req]; ok {
+				out.WriteString(ft.noise + r)
+			} else if req == requestDA1 && !ft.silent {
+				out.WriteString("\x1b[?62;22c")
+			}
+		})
+		if out.Len() > 0 {
+			_, _ = ft.master.WriteString(out.String())
+		}
+	}
+}
+
+func newQueryConsole(t *testing.T, ft *fakeTerminal) *console {
+	t.Helper()
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Fatalf("failed to open pty: %v", err)
+	}
+	t.Cleanup(func() {
+		master.Close()
+		slave.Close()
+	})
+	ft.master = master
+	go ft.serve()
+	return &console{in: slave, out: slave, err: slave, outTTY: true, errTTY: true}
+}
+
+func TestConsole_Queries(t *testing.T) {
+	ft := &fakeTerminal{replies: map[string]string{
+		"\x1b[>c":         "\x1b[>41;388;0c",
+		"\x1b[>0q":        "\x1bP>|xterm(388)\x1b\\",
+		"\x1b[?2004$p":    "\x1b[?2004;2$y",
+		"\x1b]10;?\x1b\\": "\x1b]10;rgb:cdcd/d6d6/f4f4\x1b\\",
+		"\x1b]11;?\x1b\\": "\x1b]11;rgb:1e1e/1e1e/2e2e\a",
+		"\x1b[6n":         "\x1b[12;40R",
+	}, noise: "x"}
+	c := newQueryConsole(t, ft)
+	const timeout = 2 * time.Second
+
+	da1, err := c.PrimaryDeviceAttributes(timeout)
+	if err != nil || !reflect.DeepEqual(da1, PrimaryAttributes{Level: 62, Features: []int{22}}) {
+		t.Errorf("PrimaryDeviceAttributes() = %+v, %v", da1, err)
+	}
+	da2, err := c.SecondaryDeviceAttributes(timeout)
+	if err != nil || da2 != (SecondaryAttributes{Type: 41, Version: 388}) {
+		t.Errorf("SecondaryDeviceAttributes() = %+v, %v", da2, err)
+	}
+	if v, err := c.TerminalVersion(timeout); err != nil || v != "xterm(388)" {
+		t.Errorf("TerminalVersion() = %q, %v", v, err)
+	}
+	if s, err := c.ModeStatus(ModeBracketedPaste, timeout); err != nil || s != ModeReset {
+		t.Errorf("ModeStatus(2004) = %v, %v", s, err)
+	}
+	if fg, err := c.ForegroundColor(timeout); err != nil || fg != (color.RGBA{0xcd, 0xd6, 0xf4, 0xff}) {
+		t.Errorf("ForegroundColor() = %v, %v", fg, err)
+	}
+	if bg, err := c.BackgroundColor(timeout); err != nil || bg != (color.RGBA{0x1e, 0x1e, 0x2e, 0xff}) {
+		t.Errorf("BackgroundColor() = %v, %v", bg, err)
+	}
+	if row, col, err := c.CursorPosition(timeout); err != nil || row != 12 || col != 40 {
+		t.Errorf("CursorPosition() = %d, %d, %v", row, col, err)
+	}
+	if c.raw != nil {
+		t.Error("queries left the console in raw mode")
+	}
+}
+
+func TestConsole_Query_Unsupported(t *testing.T) {
+	c := newQueryConsole(t, &fakeTerminal{})
+	start := time.Now()
+	_, err := c.TerminalVersion(5 * time.Second)
+	if !errors.Is(err, ErrNoReply) {
+		t.Errorf("TerminalVersion() error = %v, want ErrNoReply", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("took %v, want the DA1 reply to end the wait early", d)
+	}
+}
+
+func TestConsole_Query_Timeout(t *testing.T) {
+	c := newQueryConsole(t, &fakeTerminal{silent: true})
+	if _, _, err := c.CursorPosition(100 * time.Millisecond); !errors.Is(err, ErrQueryTimeout) {
+		t.Errorf("CursorPosition() error = %v, want ErrQueryTimeout", err)
+	}
+	if c.pendingDA1 != 1 {
+		t.Errorf("pendingDA1 = %d after a timeout, want 1", c.pendingDA1)
+	}
+}
+
+func TestConsole_Query_LateDA1(t *testing.T) {
+	ft := &fakeTerminal{replies: map[string]string{"\x1b[>0q": "\x1bP>|xterm(388)\x1b\\"}}
+	c := newQueryConsole(t, ft)
+	// A DA1 reply to an earlier query that timed out, arriving now.
+	c.pendingDA1 = 1
+	if _, err := ft.master.WriteString("\x1b[?1;2c"); err != nil {
+		t.Fatalf("writing the late reply failed: %v", err)
+	}
+
+	if v, err := c.TerminalVersion(2 * time.Second); err != nil || v != "xterm(388)" {
+		t.Errorf("TerminalVersion() = %q, %v; want the late DA1 reply skipped", v, err)
+	}
+	if c.pendingDA1 != 0 {
+		t.Errorf("pendingDA1 = %d, want 0", c.pendingDA1)
+	}
+	if da1, err := c.PrimaryDeviceAttributes(2 * time.Second); err != nil || da1.Level != 62 {
+		t.Errorf("PrimaryDeviceAttributes() = %+v, %v", da1, err)
+	}
+}
+
+func TestConsole_Query_NotAConsole(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	c := &console{in: r, out: w, err: w}
+	if _, err := c.PrimaryDeviceAttributes(time.Second); !errors.Is(err, ErrNotAConsole) {
+		t.Errorf("PrimaryDeviceAttributes() error = %v, want ErrNotAConsole", err)
+	}
+}
@@ -0,0 +1,91 @@
+package ptyx
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultResizeDebounce is how long RunInteractive waits for a window to
+// stop changing size before forwarding the new size to the session.
+const DefaultResizeDebounce = 50 * time.Millisecond
+
+// WatchResize returns a channel that receives the console's size after it
+// changes. Resize notifications arriving less than debounce apart are
+// coalesced, and the size is read once they stop; with a debounce of 0 every
+// notification is reported. A slow reader only sees the latest size. The
+// channel is closed when ctx is done or the console stops reporting resizes.
+func WatchResize(ctx context.Context, c Console, debounce time.Duration) <-chan Size {
+	out := make(chan Size, 1)
+	src := c.OnResize()
+	if src == nil {
+		close(out)
+		return out
+	}
+	go func() {
+		defer close(out)
+		var timer *time.Timer
+		var fire <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-src:
+				if !ok {
+					return
+				}
+				if debounce <= 0 {
+					sendLatest(out, c.SizeInfo())
+					continue
+				}
+				if timer == nil {
+					timer = time.NewTimer(debounce)
+				} else {
+					timer.Reset(debounce)
+				}
+				fire = timer.C
+			case <-fire:
+				fire = nil
+				sendLatest(out, c.SizeInfo())
+			}
+		}
+	}()
+	return out
+}
+
+// sendLatest replaces any size the reader has not picked up yet.
+func sendLatest(ch chan Size, sz Size) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- sz
+}
+
+// ForwardResize resizes s to each size received from sizes until the channel
+// is closed or ctx is done. Sizes equal to the last one applied, and sizes
+// without cells, are skipped. A failed resize is retried on the next size.
+func ForwardResize(ctx context.Context, s Session, sizes <-chan Size) error {
+	var last Size
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sz, ok := <-sizes:
+			if !ok {
+				return nil
+			}
+			if sz.Cols <= 0 || sz.Rows <= 0 || sz == last {
+				continue
+			}
+			if err := s.ResizeWithPixels(sz.Cols, sz.Rows, sz.XPixel, sz.YPixel); err != nil {
+				continue
+			}
+			last = sz
+		}
+	}
+}
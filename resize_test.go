@@ -0,0 +1,183 @@
+package ptyx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type resizeConsole struct {
+	*mockConsole
+	ch   chan struct{}
+	mu   sync.Mutex
+	size Size
+}
+
+func newResizeConsole() *resizeConsole {
+	return &resizeConsole{mockConsole: newMockConsole(""), ch: make(chan struct{}, 16)}
+}
+
+func (c *resizeConsole) OnResize() <-chan struct{} { return c.ch }
+
+func (c *resizeConsole) SizeInfo() Size {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *resizeConsole) resize(sz Size) {
+	c.mu.Lock()
+	c.size = sz
+	c.mu.Unlock()
+	c.ch <- struct{}{}
+}
+
+type resizeSession struct {
+	*mockSession
+	mu    sync.Mutex
+	sizes []Size
+	fail  bool
+}
+
+func (s *resizeSession) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		s.fail = false
+		return errors.New("resize failed")
+	}
+	s.sizes = append(s.sizes, Size{cols, rows, xpixel, ypixel})
+	return nil
+}
+
+func receiveSize(t *testing.T, ch <-chan Size) Size {
+	t.Helper()
+	select {
+	case sz := <-ch:
+		return sz
+	case <-time.After(2 * time.Second):
+		t.Fatal("no size received")
+		return Size{}
+	}
+}
+
+func TestWatchResize(t *testing.T) {
+	t.Run("NoDebounce", func(t *testing.T) {
+		c := newResizeConsole()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := WatchResize(ctx, c, 0)
+
+		c.resize(Size{Cols: 100, Rows: 30, XPixel: 1000, YPixel: 600})
+		if got, want := receiveSize(t, ch), (Size{100, 30, 1000, 600}); got != want {
+			t.Errorf("size = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Debounce", func(t *testing.T) {
+		c := newResizeConsole()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := WatchResize(ctx, c, 100*time.Millisecond)
+
+		for i := 1; i <= 5; i++ {
+			c.resize(Size{Cols: 80 + i, Rows: 24})
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got, want := receiveSize(t, ch), (Size{Cols: 85, Rows: 24}); got != want {
+			t.Errorf("size = %+v, want %+v", got, want)
+		}
+		select {
+		case sz := <-ch:
+			t.Errorf("got extra size %+v, want the drag coalesced into one", sz)
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("SlowReaderSeesLatest", func(t *testing.T) {
+		c := newResizeConsole()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := WatchResize(ctx, c, 0)
+
+		c.resize(Size{Cols: 90, Rows: 24})
+		c.resize(Size{Cols: 91, Rows: 24})
+		time.Sleep(50 * time.Millisecond)
+		if got := receiveSize(t, ch); got.Cols != 91 {
+			t.Errorf("size = %+v, want the latest", got)
+		}
+	})
+
+	t.Run("ClosedWithContext", func(t *testing.T) {
+		c := newResizeConsole()
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := WatchResize(ctx, c, time.Second)
+		cancel()
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Error("received a size, want the channel closed")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("channel not closed after cancel")
+		}
+	})
+
+	t.Run("ClosedWithConsole", func(t *testing.T) {
+		c := newResizeConsole()
+		ch := WatchResize(context.Background(), c, 0)
+		close(c.ch)
+		if _, ok := <-ch; ok {
+			t.Error("received a size, want the channel closed")
+		}
+	})
+}
+
+func TestForwardResize(t *testing.T) {
+	s := &resizeSession{mockSession: newMockSession("")}
+	sizes := make(chan Size, 8)
+	for _, sz := range []Size{
+		{Cols: 80, Rows: 24},
+		{Cols: 80, Rows: 24},
+		{},
+		{Cols: 100, Rows: 30, XPixel: 800, YPixel: 600},
+		{Cols: 100, Rows: 30, XPixel: 800, YPixel: 600},
+		{Cols: 80, Rows: 24},
+	} {
+		sizes <- sz
+	}
+	close(sizes)
+
+	if err := ForwardResize(context.Background(), s, sizes); err != nil {
+		t.Fatalf("ForwardResize() = %v", err)
+	}
+	want := []Size{{Cols: 80, Rows: 24}, {Cols: 100, Rows: 30, XPixel: 800, YPixel: 600}, {Cols: 80, Rows: 24}}
+	if !reflect.DeepEqual(s.sizes, want) {
+		t.Errorf("resizes = %+v, want %+v", s.sizes, want)
+	}
+}
+
+func TestForwardResize_RetryAfterError(t *testing.T) {
+	s := &resizeSession{mockSession: newMockSession(""), fail: true}
+	sizes := make(chan Size, 2)
+	sizes <- Size{Cols: 90, Rows: 20}
+	sizes <- Size{Cols: 90, Rows: 20}
+	close(sizes)
+
+	_ = ForwardResize(context.Background(), s, sizes)
+	if want := []Size{{Cols: 90, Rows: 20}}; !reflect.DeepEqual(s.sizes, want) {
+		t.Errorf("resizes = %+v, want %+v", s.sizes, want)
+	}
+}
+
+func TestForwardResize_ContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ForwardResize(ctx, &resizeSession{mockSession: newMockSession("")}, make(chan Size))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ForwardResize() = %v, want context.Canceled", err)
+	}
+}
@@ -2,7 +2,6 @@ package ptyx
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -18,7 +17,10 @@ func Run(ctx context.Context, opts SpawnOpts) error {
 	spawnCtx, spawnCancel := context.WithCancel(context.Background())
 	defer spawnCancel()
 
-	go func() { <-ctx.Done(); spawnCancel() }()
+	if opts.TerminatePolicy == nil {
+		stop := context.AfterFunc(ctx, spawnCancel)
+		defer stop()
+	}
 
 	s, err := spawnFunc(spawnCtx, opts)
 	if err != nil {
@@ -30,15 +32,28 @@ func Run(ctx context.Context, opts SpawnOpts) error {
 
 	select {
 	case <-ctx.Done():
-		_ = s.Close()
-		<-waitCh
-		return ctx.Err()
+		stopSession(s, opts)
+		return canceledError(<-waitCh, ctx.Err())
 	case err := <-waitCh:
 		_ = s.Close()
 		return err
 	}
 }
 
+func spawnContext(ctx context.Context, opts SpawnOpts) context.Context {
+	if opts.TerminatePolicy != nil {
+		return context.WithoutCancel(ctx)
+	}
+	return ctx
+}
+
+func stopSession(s Session, opts SpawnOpts) {
+	if opts.TerminatePolicy != nil {
+		_, _ = s.Terminate(context.Background(), *opts.TerminatePolicy)
+	}
+	_ = s.Close()
+}
+
 func RunInteractive(ctx context.Context, opts SpawnOpts) error {
 	c, err := newConsoleFunc()
 	if err != nil {
@@ -46,7 +61,7 @@ func RunInteractive(ctx context.Context, opts SpawnOpts) error {
 			return fmt.Errorf("failed to create console: %w", err)
 		}
 
-		s, spawnErr := spawnFunc(ctx, opts)
+		s, spawnErr := spawnFunc(spawnContext(ctx, opts), opts)
 		if spawnErr != nil {
 			return fmt.Errorf("spawn failed: %w", spawnErr)
 		}
@@ -55,8 +70,8 @@ func RunInteractive(ctx context.Context, opts SpawnOpts) error {
 		outDone := make(chan struct{})
 
 		go func() {
-			if n, _ := io.Copy(s.PtyWriter(), os.Stdin); n > 0 {
-				_ = s.CloseStdin()
+			if _, err := io.Copy(s.PtyWriter(), os.Stdin); err == nil {
+				_ = s.SendEOF()
 			}
 			close(inDone)
 		}()
@@ -67,33 +82,36 @@ func RunInteractive(ctx context.Context, opts SpawnOpts) error {
 
 		select {
 		case <-ctx.Done():
-			_ = s.Close()
-			<-waitCh
+			stopSession(s, opts)
+			err := <-waitCh
 			<-inDone
 			<-outDone
-			return ctx.Err()
+			return canceledError(err, ctx.Err())
 		case err := <-waitCh:
 			_ = s.Close()
 			<-inDone
 			<-outDone
-
-			var exitErr *ExitError
-			if errors.As(err, &exitErr) && exitErr.ExitCode == -1 { return nil }
 			return err
 		}
 	}
 
 	defer c.Close()
+	return RunConsole(ctx, c, opts)
+}
+
+// RunConsole runs the program on c the way RunInteractive does on the
+// process's own terminal. c stays open; the caller closes it.
+func RunConsole(ctx context.Context, c Console, opts SpawnOpts) error {
 	c.EnableVT()
 
 	if st, err := c.MakeRaw(); err == nil {
 		defer c.Restore(st)
 	}
 
-	w, h := c.Size()
-	opts.Cols, opts.Rows = w, h
+	sz := c.SizeInfo()
+	opts.Cols, opts.Rows, opts.XPixel, opts.YPixel = sz.Cols, sz.Rows, sz.XPixel, sz.YPixel
 
-	s, err := spawnFunc(ctx, opts)
+	s, err := spawnFunc(spawnContext(ctx, opts), opts)
 	if err != nil {
 		return fmt.Errorf("spawn failed: %w", err)
 	}
@@ -105,30 +123,17 @@ func RunInteractive(ctx context.Context, opts SpawnOpts) error {
 	}
 	defer m.Stop()
 
-	if ch := c.OnResize(); ch != nil {
-		go func(ch <-chan struct{}) {
-			for {
-				select {
-				case _, ok := <-ch:
-					if !ok {
-						return
-					}
-					_ = s.Resize(c.Size())
-				case <-ctx.Done():
-					return
-				}
-			}
-		}(ch)
-	}
+	resizeCtx, stopResize := context.WithCancel(ctx)
+	defer stopResize()
+	go func() { _ = ForwardResize(resizeCtx, s, WatchResize(resizeCtx, c, DefaultResizeDebounce)) }()
 
 	waitCh := make(chan error, 1)
 	go func() { waitCh <- s.Wait() }()
 
 	select {
 	case <-ctx.Done():
-		_ = s.Close()
-		<-waitCh
-		return ctx.Err()
+		stopSession(s, opts)
+		return canceledError(<-waitCh, ctx.Err())
 	case err := <-waitCh:
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"os/exec"
 	"runtime"
 	"strings"
 	"testing"
@@ -55,6 +56,26 @@ func TestRun(t *testing.T) {
 		}
 	})
 
+	t.Run("NoGoroutineLeak", func(t *testing.T) {
+		originalSpawn := spawnFunc
+		spawnFunc = func(ctx context.Context, opts SpawnOpts) (Session, error) { return &mockSession{}, nil }
+		t.Cleanup(func() { spawnFunc = originalSpawn })
+
+		before := runtime.NumGoroutine()
+		for range 50 {
+			if err := Run(context.Background(), baseOpts); err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+		}
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before+5 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if n := runtime.NumGoroutine(); n > before+5 {
+			t.Errorf("goroutines grew from %d to %d over 50 runs", before, n)
+		}
+	})
+
 	t.Run("ContextCancel", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 		defer cancel()
@@ -66,6 +87,28 @@ func TestRun(t *testing.T) {
 		if !errors.Is(err, context.DeadlineExceeded) {
 			t.Errorf("Run() error = %v, want context.DeadlineExceeded", err)
 		}
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || !exitErr.Canceled {
+			t.Errorf("Run() error = %v, want a canceled *ptyx.ExitError", err)
+		}
+	})
+
+	t.Run("ContextCancel_TerminatePolicy", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		opts := baseOpts
+		opts.Env = append(opts.Env, "MODE=sleep")
+		opts.TerminatePolicy = &TerminatePolicy{HangupGrace: time.Second, TermGrace: time.Second}
+		start := time.Now()
+		err := Run(ctx, opts)
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Run() error = %v, want context.DeadlineExceeded", err)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("Run() took %v to terminate the process", elapsed)
+		}
 	})
 }
 
@@ -110,6 +153,36 @@ func TestRunInteractive_NonConsole(t *testing.T) {
 	}
 }
 
+func TestRunInteractive_NonConsole_EmptyStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping non-console interactive test on Windows; will be covered in the future.")
+	}
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skipf("could not find 'cat', skipping test: %v", err)
+	}
+	originalNewConsole := newConsoleFunc
+	newConsoleFunc = func() (Console, error) {
+		return nil, ErrNotAConsole
+	}
+	t.Cleanup(func() { newConsoleFunc = originalNewConsole })
+
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("opening %s failed: %v", os.DevNull, err)
+	}
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	// cat only exits once it reads the end of an input that was empty.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := RunInteractive(ctx, SpawnOpts{Prog: "cat"}); err != nil {
+		t.Fatalf("RunInteractive() with empty stdin = %v, want nil", err)
+	}
+}
+
 func TestRunInteractive_Console(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		// TODO: 중첩된 PTY 환경에서 발생하는 데드락 문제로 인해 Windows에서 비활성화합니다.
@@ -233,6 +306,50 @@ func TestRunInteractive_ErrorPaths(t *testing.T) {
 		}
 	})
 
+	t.Run("NonConsole_ContextCancel_TerminatePolicy", func(t *testing.T) {
+		originalNewConsole := newConsoleFunc
+		newConsoleFunc = func() (Console, error) {
+			return nil, ErrNotAConsole
+		}
+		t.Cleanup(func() { newConsoleFunc = originalNewConsole })
+
+		mockSess := newMockSession("")
+		waitCh := make(chan struct{})
+		mockSess.waitFunc = func() error {
+			<-waitCh
+			return nil
+		}
+		var got *TerminatePolicy
+		mockSess.terminateFunc = func(p TerminatePolicy) (TerminateStep, error) {
+			got = &p
+			close(waitCh)
+			return TerminateHangup, nil
+		}
+		var spawnCtx context.Context
+		originalSpawn := spawnFunc
+		spawnFunc = func(ctx context.Context, opts SpawnOpts) (Session, error) {
+			spawnCtx = ctx
+			return mockSess, nil
+		}
+		t.Cleanup(func() { spawnFunc = originalSpawn })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		opts := baseOpts
+		opts.TerminatePolicy = &DefaultTerminatePolicy
+		// The program exited with status 0 on the hangup, so that is no error.
+		if err := RunInteractive(ctx, opts); err != nil {
+			t.Errorf("RunInteractive() error = %v, want nil", err)
+		}
+		if got == nil || *got != DefaultTerminatePolicy {
+			t.Errorf("Terminate() policy = %v, want %v", got, DefaultTerminatePolicy)
+		}
+		if spawnCtx.Err() != nil {
+			t.Error("spawn context should not be cancelled when a TerminatePolicy is set")
+		}
+	})
+
 	t.Run("Console_SpawnError", func(t *testing.T) {
 		originalNewConsole := newConsoleFunc
 		newConsoleFunc = func() (Console, error) {
@@ -307,3 +424,34 @@ func TestRunInteractive_ErrorPaths(t *testing.T) {
 		}
 	})
 }
+
+type closeCountingConsole struct {
+	*mockConsole
+	closed int
+}
+
+func (c *closeCountingConsole) Close() error {
+	c.closed++
+	return c.mockConsole.Close()
+}
+
+func TestRunConsole(t *testing.T) {
+	var got SpawnOpts
+	originalSpawn := spawnFunc
+	spawnFunc = func(ctx context.Context, opts SpawnOpts) (Session, error) {
+		got = opts
+		return newMockSession(""), nil
+	}
+	t.Cleanup(func() { spawnFunc = originalSpawn })
+
+	c := &closeCountingConsole{mockConsole: newMockConsole("")}
+	if err := RunConsole(context.Background(), c, SpawnOpts{Prog: "prog"}); err != nil {
+		t.Fatalf("RunConsole() = %v", err)
+	}
+	if got.Prog != "prog" || got.Cols != 80 || got.Rows != 24 {
+		t.Errorf("spawned with %+v, want prog at the console's 80x24", got)
+	}
+	if c.closed != 0 {
+		t.Errorf("Close() called %d times, want the console left to the caller", c.closed)
+	}
+}
@@ -0,0 +1,10 @@
+package ptyx
+
+// Size is a terminal size in cells and, where known, in pixels. XPixel and
+// YPixel are 0 when the terminal does not report them.
+type Size struct {
+	Cols   int
+	Rows   int
+	XPixel int
+	YPixel int
+}
@@ -0,0 +1,12 @@
+package ptyx
+
+// StreamMode selects what a child's standard stream is connected to.
+type StreamMode int
+
+const (
+	// StreamPTY connects the stream to the PTY. It is the default.
+	StreamPTY StreamMode = iota
+	// StreamPipe connects the stream to a pipe, available from the
+	// session's StdinPipe, StdoutPipe or StderrPipe.
+	StreamPipe
+)
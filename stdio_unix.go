@@ -0,0 +1,94 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+)
+
+type stdioPipes struct {
+	stdin          *os.File
+	stdout, stderr *os.File
+}
+
+func (p stdioPipes) close() {
+	for _, f := range []*os.File{p.stdin, p.stdout, p.stderr} {
+		if f != nil {
+			_ = f.Close()
+		}
+	}
+}
+
+// setupStdio connects the child's standard streams to the PTY slave, a new
+// pipe or the caller's reader or writer, and makes one of the child's PTY
+// descriptors its controlling terminal. It returns our ends of any pipes and
+// the child's ends, which must be closed once the child has started.
+func setupStdio(cmd *exec.Cmd, slave *os.File, opts SpawnOpts) (p stdioPipes, child []*os.File, err error) {
+	defer func() {
+		if err != nil {
+			p.close()
+			closeFiles(child)
+		}
+	}()
+
+	ctty := -1
+	switch {
+	case opts.Stdin != nil:
+		cmd.Stdin = opts.Stdin
+	case opts.StdinMode == StreamPipe:
+		r, w, err := os.Pipe()
+		if err != nil {
+			return p, child, err
+		}
+		cmd.Stdin, p.stdin = r, w
+		child = append(child, r)
+	default:
+		cmd.Stdin, ctty = slave, 0
+	}
+
+	outputs := []struct {
+		fd     int
+		w      io.Writer
+		mode   StreamMode
+		target *io.Writer
+		pipe   **os.File
+	}{
+		{1, opts.Stdout, opts.StdoutMode, &cmd.Stdout, &p.stdout},
+		{2, opts.Stderr, opts.StderrMode, &cmd.Stderr, &p.stderr},
+	}
+	for _, o := range outputs {
+		switch {
+		case o.w != nil:
+			*o.target = o.w
+		case o.mode == StreamPipe:
+			r, w, err := os.Pipe()
+			if err != nil {
+				return p, child, err
+			}
+			*o.target, *o.pipe = w, r
+			child = append(child, w)
+		default:
+			*o.target = slave
+			if ctty < 0 {
+				ctty = o.fd
+			}
+		}
+	}
+
+	if ctty < 0 {
+		// Clip so that a caller's slice with spare capacity is not written to.
+		cmd.ExtraFiles = append(slices.Clip(cmd.ExtraFiles), slave)
+		ctty = 2 + len(cmd.ExtraFiles)
+	}
+	cmd.SysProcAttr.Ctty = ctty
+	return p, child, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
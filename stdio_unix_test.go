@@ -0,0 +1,96 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func spawnSh(t *testing.T, script string, opts SpawnOpts) Session {
+	t.Helper()
+	opts.Prog, opts.Args = "sh", []string{"-c", script}
+	s, err := Spawn(context.Background(), opts)
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestUnixSpawn_StderrPipe(t *testing.T) {
+	s := spawnSh(t, "echo out; echo err >&2", SpawnOpts{StderrMode: StreamPipe})
+	if s.StdinPipe() != nil || s.StdoutPipe() != nil {
+		t.Error("only the stderr pipe should be set")
+	}
+
+	ptyOut := make(chan string, 1)
+	go func() {
+		b, _ := io.ReadAll(s.PtyReader())
+		ptyOut <- string(b)
+	}()
+	stderr, err := io.ReadAll(s.StderrPipe())
+	if err != nil {
+		t.Fatalf("reading StderrPipe() failed: %v", err)
+	}
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	if got := string(stderr); got != "err\n" {
+		t.Errorf("stderr = %q, want %q", got, "err\n")
+	}
+	if got := <-ptyOut; !strings.Contains(got, "out") || strings.Contains(got, "err") {
+		t.Errorf("pty output = %q, want only stdout", got)
+	}
+}
+
+func TestUnixSpawn_StdinPipe(t *testing.T) {
+	s := spawnSh(t, "cat; echo done", SpawnOpts{StdinMode: StreamPipe})
+
+	ptyOut := make(chan string, 1)
+	go func() {
+		b, _ := io.ReadAll(s.PtyReader())
+		ptyOut <- string(b)
+	}()
+	if _, err := io.WriteString(s.StdinPipe(), "from pipe\n"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	_ = s.StdinPipe().Close()
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if got := <-ptyOut; !strings.Contains(got, "from pipe") || !strings.Contains(got, "done") {
+		t.Errorf("pty output = %q", got)
+	}
+}
+
+func TestUnixSpawn_UserStreams(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	// With no stream on the PTY, the slave is passed as an extra descriptor
+	// so the child still gets a controlling terminal.
+	s := spawnSh(t, "cat; : </dev/tty && echo has-ctty >&2", SpawnOpts{
+		Stdin:  strings.NewReader("hello"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	go io.Copy(io.Discard, s.PtyReader())
+
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if got := stdout.String(); got != "hello" {
+		t.Errorf("stdout = %q, want %q", got, "hello")
+	}
+	if got := stderr.String(); got != "has-ctty\n" {
+		t.Errorf("stderr = %q, want %q", got, "has-ctty\n")
+	}
+}
@@ -2,8 +2,25 @@
 
 package ptyx
 
-import "syscall"
+import (
+	"errors"
+	"syscall"
+)
 
 func newSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
 }
+
+// mergeSysProcAttr returns a copy of attr that also makes the child a
+// session leader with the PTY as its controlling terminal.
+func mergeSysProcAttr(attr *syscall.SysProcAttr) (*syscall.SysProcAttr, error) {
+	if attr == nil {
+		return newSysProcAttr(), nil
+	}
+	if attr.Setpgid || attr.Foreground || attr.Noctty {
+		return nil, errors.New("ptyx: SysProcAttr.Setpgid, Foreground and Noctty conflict with the PTY session")
+	}
+	merged := *attr
+	merged.Setsid, merged.Setctty = true, true
+	return &merged, nil
+}
@@ -0,0 +1,41 @@
+package ptyx
+
+import (
+	"fmt"
+	"time"
+)
+
+// TerminatePolicy controls how Session.Terminate escalates. Each grace period
+// is how long to wait for the process to exit after that signal before moving
+// on; a zero grace period skips the signal. SIGKILL is always the last step.
+type TerminatePolicy struct {
+	HangupGrace time.Duration
+	TermGrace   time.Duration
+}
+
+var DefaultTerminatePolicy = TerminatePolicy{HangupGrace: time.Second, TermGrace: 2 * time.Second}
+
+// TerminateStep reports which step of a TerminatePolicy ended the process.
+type TerminateStep int
+
+const (
+	// TerminateNone means the process had already exited.
+	TerminateNone TerminateStep = iota
+	TerminateHangup
+	TerminateTerm
+	TerminateKill
+)
+
+func (s TerminateStep) String() string {
+	switch s {
+	case TerminateNone:
+		return "none"
+	case TerminateHangup:
+		return "SIGHUP"
+	case TerminateTerm:
+		return "SIGTERM"
+	case TerminateKill:
+		return "SIGKILL"
+	}
+	return fmt.Sprintf("TerminateStep(%d)", int(s))
+}
@@ -0,0 +1,21 @@
+package ptyx
+
+import "testing"
+
+func TestTerminateStep_String(t *testing.T) {
+	tests := []struct {
+		step TerminateStep
+		want string
+	}{
+		{TerminateNone, "none"},
+		{TerminateHangup, "SIGHUP"},
+		{TerminateTerm, "SIGTERM"},
+		{TerminateKill, "SIGKILL"},
+		{TerminateStep(9), "TerminateStep(9)"},
+	}
+	for _, tt := range tests {
+		if got := tt.step.String(); got != tt.want {
+			t.Errorf("TerminateStep(%d).String() = %q, want %q", int(tt.step), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package ptyx
+
+// Termios is the portable subset of the PTY line discipline settings.
+// A special character of 0 disables it.
+type Termios struct {
+	Echo      bool // ECHO: echo input back to the output
+	Canonical bool // ICANON: line editing; input is delivered a line at a time
+	ISig      bool // ISIG: Intr, Quit and Susp generate signals
+	IUTF8     bool // IUTF8: erase whole UTF-8 characters, where supported
+	ONLCR     bool // ONLCR: translate output \n to \r\n
+
+	Intr  byte // VINTR
+	Quit  byte // VQUIT
+	Erase byte // VERASE
+	Kill  byte // VKILL
+	EOF   byte // VEOF
+	Susp  byte // VSUSP
+}
+
+// DefaultTermios is the cooked mode a new PTY typically starts in.
+var DefaultTermios = Termios{
+	Echo:      true,
+	Canonical: true,
+	ISig:      true,
+	IUTF8:     true,
+	ONLCR:     true,
+	Intr:      0x03,
+	Quit:      0x1c,
+	Erase:     0x7f,
+	Kill:      0x15,
+	EOF:       0x04,
+	Susp:      0x1a,
+}
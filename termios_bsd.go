@@ -0,0 +1,12 @@
+//go:build freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlReadTermios  = unix.TIOCGETA
+	ioctlWriteTermios = unix.TIOCSETA
+	termiosIUTF8      = 0
+	posixVDisable     = 0xff
+)
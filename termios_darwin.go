@@ -0,0 +1,12 @@
+//go:build darwin
+
+package ptyx
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlReadTermios  = unix.TIOCGETA
+	ioctlWriteTermios = unix.TIOCSETA
+	termiosIUTF8      = unix.IUTF8
+	posixVDisable     = 0xff
+)
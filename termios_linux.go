@@ -0,0 +1,12 @@
+//go:build linux
+
+package ptyx
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlReadTermios  = unix.TCGETS
+	ioctlWriteTermios = unix.TCSETS
+	termiosIUTF8      = unix.IUTF8
+	posixVDisable     = 0
+)
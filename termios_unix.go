@@ -0,0 +1,68 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import "golang.org/x/sys/unix"
+
+func getTermios(fd int) (Termios, error) {
+	tio, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return Termios{}, err
+	}
+	return Termios{
+		Echo:      tio.Lflag&unix.ECHO != 0,
+		Canonical: tio.Lflag&unix.ICANON != 0,
+		ISig:      tio.Lflag&unix.ISIG != 0,
+		IUTF8:     termiosIUTF8 != 0 && tio.Iflag&termiosIUTF8 != 0,
+		ONLCR:     tio.Oflag&unix.ONLCR != 0,
+		Intr:      fromCC(tio.Cc[unix.VINTR]),
+		Quit:      fromCC(tio.Cc[unix.VQUIT]),
+		Erase:     fromCC(tio.Cc[unix.VERASE]),
+		Kill:      fromCC(tio.Cc[unix.VKILL]),
+		EOF:       fromCC(tio.Cc[unix.VEOF]),
+		Susp:      fromCC(tio.Cc[unix.VSUSP]),
+	}, nil
+}
+
+// setTermios changes only the settings Termios covers and leaves the rest
+// of the line discipline as it is.
+func setTermios(fd int, t Termios) error {
+	tio, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return err
+	}
+	setFlag(&tio.Lflag, unix.ECHO, t.Echo)
+	setFlag(&tio.Lflag, unix.ICANON, t.Canonical)
+	setFlag(&tio.Lflag, unix.ISIG, t.ISig)
+	setFlag(&tio.Iflag, termiosIUTF8, t.IUTF8)
+	setFlag(&tio.Oflag, unix.ONLCR, t.ONLCR)
+	tio.Cc[unix.VINTR] = toCC(t.Intr)
+	tio.Cc[unix.VQUIT] = toCC(t.Quit)
+	tio.Cc[unix.VERASE] = toCC(t.Erase)
+	tio.Cc[unix.VKILL] = toCC(t.Kill)
+	tio.Cc[unix.VEOF] = toCC(t.EOF)
+	tio.Cc[unix.VSUSP] = toCC(t.Susp)
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, tio)
+}
+
+func setFlag[T uint32 | uint64](v *T, bit T, on bool) {
+	if on {
+		*v |= bit
+	} else {
+		*v &^= bit
+	}
+}
+
+func fromCC(c uint8) byte {
+	if c == posixVDisable {
+		return 0
+	}
+	return c
+}
+
+func toCC(c byte) uint8 {
+	if c == 0 {
+		return posixVDisable
+	}
+	return c
+}
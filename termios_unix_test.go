@@ -0,0 +1,100 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package ptyx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestTermios_RoundTrip(t *testing.T) {
+	m, s, err := openPTY()
+	if err != nil {
+		t.Fatalf("openPTY() failed: %v", err)
+	}
+	defer m.Close()
+	defer s.Close()
+
+	want := Termios{ONLCR: true, Intr: 0x18, Erase: 0x08, EOF: 0x04}
+	if err := setTermios(int(s.Fd()), want); err != nil {
+		t.Fatalf("setTermios() failed: %v", err)
+	}
+	got, err := getTermios(int(m.Fd()))
+	if err != nil {
+		t.Fatalf("getTermios() failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("getTermios() = %+v, want %+v", got, want)
+	}
+
+	if termiosIUTF8 == 0 {
+		return
+	}
+	want.IUTF8 = true
+	if err := setTermios(int(s.Fd()), want); err != nil {
+		t.Fatalf("setTermios() failed: %v", err)
+	}
+	if got, _ := getTermios(int(s.Fd())); !got.IUTF8 {
+		t.Error("IUTF8 was not set")
+	}
+}
+
+func TestUnixSpawn_Termios(t *testing.T) {
+	tio := DefaultTermios
+	tio.Echo = false
+	tio.Intr = 0x18
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "stty", Args: []string{"-a"}, Termios: &tio})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'stty', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+
+	got, err := s.Termios()
+	if err != nil {
+		t.Fatalf("Termios() failed: %v", err)
+	}
+	if got.Echo || got.Intr != 0x18 || !got.Canonical {
+		t.Errorf("Termios() = %+v, want echo off and intr ^X", got)
+	}
+
+	out, _ := io.ReadAll(s.PtyReader())
+	_ = s.Wait()
+	if !strings.Contains(string(out), "intr = ^X") || !strings.Contains(string(out), "-echo ") {
+		t.Errorf("stty -a output = %q, want intr = ^X and -echo", out)
+	}
+}
+
+func TestUnixSession_SetTermios(t *testing.T) {
+	s, err := Spawn(context.Background(), SpawnOpts{Prog: "sleep", Args: []string{"5"}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sleep', skipping test: %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer s.Close()
+	defer s.Kill()
+
+	tio, err := s.Termios()
+	if err != nil {
+		t.Fatalf("Termios() failed: %v", err)
+	}
+	tio.Canonical, tio.Echo, tio.Quit = false, false, 0
+	if err := s.SetTermios(tio); err != nil {
+		t.Fatalf("SetTermios() failed: %v", err)
+	}
+	got, err := s.Termios()
+	if err != nil {
+		t.Fatalf("Termios() failed: %v", err)
+	}
+	if got != tio {
+		t.Errorf("Termios() = %+v, want %+v", got, tio)
+	}
+}
@@ -2,8 +2,11 @@ package ptyx
 
 import (
 	"bytes"
+	"context"
+	"image/color"
 	"io"
 	"os"
+	"time"
 )
 
 type mockConsole struct {
@@ -27,18 +30,42 @@ func (m *mockConsole) Out() io.Writer            { return m.outBuf }
 func (m *mockConsole) Err() *os.File             { panic("not implemented") }
 func (m *mockConsole) IsATTYOut() bool           { return true }
 func (m *mockConsole) Size() (int, int)          { return 80, 24 }
+func (m *mockConsole) SizeInfo() Size            { return Size{Cols: 80, Rows: 24} }
 func (m *mockConsole) MakeRaw() (RawState, error)  { return nil, nil }
 func (m *mockConsole) Restore(RawState) error      { return nil }
 func (m *mockConsole) EnableVT()                 {}
 func (m *mockConsole) OnResize() <-chan struct{}   { return make(chan struct{}) }
 func (m *mockConsole) Close() error              { return m.in.Close() }
+func (m *mockConsole) PrimaryDeviceAttributes(time.Duration) (PrimaryAttributes, error) {
+	return PrimaryAttributes{}, ErrUnsupported
+}
+func (m *mockConsole) SecondaryDeviceAttributes(time.Duration) (SecondaryAttributes, error) {
+	return SecondaryAttributes{}, ErrUnsupported
+}
+func (m *mockConsole) TerminalVersion(time.Duration) (string, error) { return "", ErrUnsupported }
+func (m *mockConsole) ModeStatus(int, time.Duration) (ModeState, error) {
+	return ModeNotRecognized, ErrUnsupported
+}
+func (m *mockConsole) ForegroundColor(time.Duration) (color.RGBA, error) { return color.RGBA{}, ErrUnsupported }
+func (m *mockConsole) BackgroundColor(time.Duration) (color.RGBA, error) { return color.RGBA{}, ErrUnsupported }
+func (m *mockConsole) CursorPosition(time.Duration) (int, int, error)    { return 0, 0, ErrUnsupported }
+func (m *mockConsole) EnableKittyKeyboard(KittyFlags) error              { return nil }
+func (m *mockConsole) DisableKittyKeyboard() error                       { return nil }
+func (m *mockConsole) EnableMouse(MouseTracking, MouseEncoding) error    { return nil }
+func (m *mockConsole) DisableMouse() error                               { return nil }
+func (m *mockConsole) EnableBracketedPaste() error                       { return nil }
+func (m *mockConsole) DisableBracketedPaste() error                      { return nil }
+func (m *mockConsole) EnableFocusEvents() error                          { return nil }
+func (m *mockConsole) DisableFocusEvents() error                         { return nil }
 
 type mockSession struct {
 	ptyIn  *bytes.Buffer
 	ptyOut io.Reader
 	closeStdinFunc func() error
+	sendEOFFunc    func() error
 	waitFunc       func() error
 	closeFunc      func() error
+	terminateFunc  func(TerminatePolicy) (TerminateStep, error)
 }
 
 func newMockSession(output string) *mockSession {
@@ -51,6 +78,9 @@ func newMockSession(output string) *mockSession {
 func (m *mockSession) PtyReader() io.Reader      { return m.ptyOut }
 func (m *mockSession) PtyWriter() io.Writer      { return m.ptyIn }
 func (m *mockSession) Resize(cols, rows int) error { return nil }
+func (m *mockSession) ResizeWithPixels(cols, rows, xpixel, ypixel int) error { return nil }
+func (m *mockSession) SendKeys(keys ...any) error                           { return nil }
+func (m *mockSession) KeyModes() KeyModes                                   { return KeyModes{} }
 func (m *mockSession) Wait() error {
 	if m.waitFunc != nil {
 		return m.waitFunc()
@@ -71,6 +101,25 @@ func (m *mockSession) CloseStdin() error {
 	}
 	return nil
 }
+func (m *mockSession) SendEOF() error {
+	if m.sendEOFFunc != nil {
+		return m.sendEOFFunc()
+	}
+	return nil
+}
+func (m *mockSession) StdinPipe() io.WriteCloser { return nil }
+func (m *mockSession) StdoutPipe() io.ReadCloser { return nil }
+func (m *mockSession) StderrPipe() io.ReadCloser { return nil }
+func (m *mockSession) Signal(sig os.Signal) error           { return nil }
+func (m *mockSession) SignalForeground(sig os.Signal) error { return nil }
+func (m *mockSession) Termios() (Termios, error) { return DefaultTermios, nil }
+func (m *mockSession) SetTermios(t Termios) error  { return nil }
+func (m *mockSession) Terminate(ctx context.Context, p TerminatePolicy) (TerminateStep, error) {
+	if m.terminateFunc != nil {
+		return m.terminateFunc(p)
+	}
+	return TerminateNone, nil
+}
 
 type mockMux struct {
 	startErr error
@@ -2,8 +2,11 @@ package testptyx
 
 import (
 	"bytes"
+	"context"
+	"image/color"
 	"io"
 	"os"
+	"time"
 
 	"github.com/KennethanCeyer/ptyx"
 )
@@ -35,6 +38,7 @@ func (m *MockConsole) Err() *os.File {
 }
 func (m *MockConsole) IsATTYOut() bool           { return true }
 func (m *MockConsole) Size() (int, int)          { return 80, 24 }
+func (m *MockConsole) SizeInfo() ptyx.Size       { return ptyx.Size{Cols: 80, Rows: 24} }
 func (m *MockConsole) MakeRaw() (ptyx.RawState, error) {
 	return nil, m.MakeRawError
 }
@@ -46,6 +50,33 @@ func (m *MockConsole) OnResize() <-chan struct{} {
 	return ch
 }
 func (m *MockConsole) Close() error              { return nil }
+func (m *MockConsole) PrimaryDeviceAttributes(time.Duration) (ptyx.PrimaryAttributes, error) {
+	return ptyx.PrimaryAttributes{}, ptyx.ErrUnsupported
+}
+func (m *MockConsole) SecondaryDeviceAttributes(time.Duration) (ptyx.SecondaryAttributes, error) {
+	return ptyx.SecondaryAttributes{}, ptyx.ErrUnsupported
+}
+func (m *MockConsole) TerminalVersion(time.Duration) (string, error) { return "", ptyx.ErrUnsupported }
+func (m *MockConsole) ModeStatus(int, time.Duration) (ptyx.ModeState, error) {
+	return ptyx.ModeNotRecognized, ptyx.ErrUnsupported
+}
+func (m *MockConsole) ForegroundColor(time.Duration) (color.RGBA, error) {
+	return color.RGBA{}, ptyx.ErrUnsupported
+}
+func (m *MockConsole) BackgroundColor(time.Duration) (color.RGBA, error) {
+	return color.RGBA{}, ptyx.ErrUnsupported
+}
+func (m *MockConsole) CursorPosition(time.Duration) (int, int, error) { return 0, 0, ptyx.ErrUnsupported }
+func (m *MockConsole) EnableKittyKeyboard(ptyx.KittyFlags) error     { return nil }
+func (m *MockConsole) DisableKittyKeyboard() error                   { return nil }
+func (m *MockConsole) EnableMouse(ptyx.MouseTracking, ptyx.MouseEncoding) error {
+	return nil
+}
+func (m *MockConsole) DisableMouse() error          { return nil }
+func (m *MockConsole) EnableBracketedPaste() error  { return nil }
+func (m *MockConsole) DisableBracketedPaste() error { return nil }
+func (m *MockConsole) EnableFocusEvents() error     { return nil }
+func (m *MockConsole) DisableFocusEvents() error    { return nil }
 
 type MockSession struct {
 	PtyInBuffer     *bytes.Buffer
@@ -69,6 +100,16 @@ func (m *MockSession) PtyWriter() io.Writer {
 	return m.PtyInBuffer
 }
 func (m *MockSession) Resize(cols, rows int) error { return nil }
+func (m *MockSession) ResizeWithPixels(cols, rows, xpixel, ypixel int) error { return nil }
+func (m *MockSession) KeyModes() ptyx.KeyModes                              { return ptyx.KeyModes{} }
+func (m *MockSession) SendKeys(keys ...any) error {
+	b, err := ptyx.EncodeKeys(m.KeyModes(), keys...)
+	if err != nil {
+		return err
+	}
+	_, err = m.PtyWriter().Write(b)
+	return err
+}
 func (m *MockSession) Wait() error {
 	return m.WaitError
 }
@@ -76,6 +117,17 @@ func (m *MockSession) Kill() error                 { return nil }
 func (m *MockSession) Close() error                { return nil }
 func (m *MockSession) Pid() int                    { return 1234 }
 func (m *MockSession) CloseStdin() error           { return nil }
+func (m *MockSession) SendEOF() error              { return nil }
+func (m *MockSession) StdinPipe() io.WriteCloser { return nil }
+func (m *MockSession) StdoutPipe() io.ReadCloser { return nil }
+func (m *MockSession) StderrPipe() io.ReadCloser { return nil }
+func (m *MockSession) Signal(sig os.Signal) error           { return nil }
+func (m *MockSession) SignalForeground(sig os.Signal) error { return nil }
+func (m *MockSession) Termios() (ptyx.Termios, error) { return ptyx.DefaultTermios, nil }
+func (m *MockSession) SetTermios(t ptyx.Termios) error  { return nil }
+func (m *MockSession) Terminate(ctx context.Context, p ptyx.TerminatePolicy) (ptyx.TerminateStep, error) {
+	return ptyx.TerminateNone, nil
+}
 
 type errorWriter struct {
 	err error
@@ -0,0 +1,65 @@
+package typescript
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx"
+)
+
+type PlayOpts struct {
+	// Speed scales playback; 2 plays twice as fast. Zero means 1.
+	Speed float64
+	// MaxDelay caps pauses between events. Zero means no cap.
+	MaxDelay time.Duration
+}
+
+// Play writes the output stream of a typescript to the console, following
+// the delays in its timing file like scriptreplay(1). Every event's delay is
+// observed, including those of input and other events that are not shown,
+// since advanced timing files measure each delay from the previous event.
+func Play(ctx context.Context, c ptyx.Console, timing, out io.Reader, opts PlayOpts) error {
+	r := NewReader(timing, out, nil)
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		d := e.Delay
+		if opts.MaxDelay > 0 && d > opts.MaxDelay {
+			d = opts.MaxDelay
+		}
+		if err := sleep(ctx, time.Duration(float64(d)/speed)); err != nil {
+			return err
+		}
+		if e.Type != EventOutput {
+			continue
+		}
+		if _, err := c.Out().Write(e.Data); err != nil {
+			return err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package typescript
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx/testptyx"
+)
+
+const (
+	timingFile = "H 0 TERM xterm\nO 0.01 6\nI 0.02 1\nS 5.0 SIGWINCH ROWS=30 COLS=100\nO 5.0 5\n"
+	outFile    = StartPrefix + "now\nhello world"
+)
+
+func TestPlay(t *testing.T) {
+	c := testptyx.NewMockConsole("")
+	start := time.Now()
+	opts := PlayOpts{Speed: 2, MaxDelay: 50 * time.Millisecond}
+	if err := Play(context.Background(), c, strings.NewReader(timingFile), strings.NewReader(outFile), opts); err != nil {
+		t.Fatalf("Play() failed: %v", err)
+	}
+	if got := c.OutBuffer.String(); got != "hello world" {
+		t.Errorf("output = %q, want %q", got, "hello world")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Play() took %v, MaxDelay not applied", elapsed)
+	}
+}
+
+func TestPlay_InputDelay(t *testing.T) {
+	timing := "O 0 5\nI 0.3 1\nO 0 6\n"
+	c := testptyx.NewMockConsole("")
+	start := time.Now()
+	if err := Play(context.Background(), c, strings.NewReader(timing), strings.NewReader(outFile), PlayOpts{Speed: 2}); err != nil {
+		t.Fatalf("Play() failed: %v", err)
+	}
+	if got := c.OutBuffer.String(); got != "hello world" {
+		t.Errorf("output = %q, want %q", got, "hello world")
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Play() took %v, want the input event's 150ms delay", elapsed)
+	}
+}
+
+func TestPlay_Cancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	c := testptyx.NewMockConsole("")
+	err := Play(ctx, c, strings.NewReader(timingFile), strings.NewReader(outFile), PlayOpts{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Play() error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := c.OutBuffer.String(); got != "hello " {
+		t.Errorf("output = %q, want %q", got, "hello ")
+	}
+}
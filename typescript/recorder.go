@@ -0,0 +1,71 @@
+package typescript
+
+import (
+	"io"
+
+	"github.com/KennethanCeyer/ptyx"
+)
+
+// Recorder is a Session that logs everything read from its PtyReader,
+// written to its PtyWriter and every Resize to a Writer.
+type Recorder struct {
+	ptyx.Session
+	w *Writer
+}
+
+func Record(s ptyx.Session, w *Writer) *Recorder {
+	return &Recorder{Session: s, w: w}
+}
+
+func (r *Recorder) PtyReader() io.Reader { return recordReader{r.Session.PtyReader(), r.w} }
+func (r *Recorder) PtyWriter() io.Writer { return recordWriter{r.Session.PtyWriter(), r.w} }
+
+// SendKeys sends keys through the recorder so they are logged as input.
+func (r *Recorder) SendKeys(keys ...any) error {
+	b, err := ptyx.EncodeKeys(r.KeyModes(), keys...)
+	if err != nil {
+		return err
+	}
+	_, err = r.PtyWriter().Write(b)
+	return err
+}
+
+func (r *Recorder) Resize(cols, rows int) error {
+	if err := r.Session.Resize(cols, rows); err != nil {
+		return err
+	}
+	return r.w.WriteResize(cols, rows)
+}
+
+func (r *Recorder) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	if err := r.Session.ResizeWithPixels(cols, rows, xpixel, ypixel); err != nil {
+		return err
+	}
+	return r.w.WriteResize(cols, rows)
+}
+
+type recordReader struct {
+	r io.Reader
+	w *Writer
+}
+
+func (rr recordReader) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	if n > 0 {
+		_ = rr.w.WriteOutput(p[:n])
+	}
+	return n, err
+}
+
+type recordWriter struct {
+	w   io.Writer
+	log *Writer
+}
+
+func (rw recordWriter) Write(p []byte) (int, error) {
+	n, err := rw.w.Write(p)
+	if n > 0 {
+		_ = rw.log.WriteInput(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,45 @@
+package typescript
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx"
+	"github.com/KennethanCeyer/ptyx/testptyx"
+)
+
+func TestRecorder(t *testing.T) {
+	s := testptyx.NewMockSession("hello")
+	var out, in, timing bytes.Buffer
+	w, err := newWriter(&out, &in, &timing, FormatAdvanced, fakeClock(time.Second))
+	if err != nil {
+		t.Fatalf("newWriter() failed: %v", err)
+	}
+	rec := Record(s, w)
+
+	if _, err := io.Copy(io.Discard, rec.PtyReader()); err != nil {
+		t.Fatalf("reading PtyReader failed: %v", err)
+	}
+	if _, err := rec.PtyWriter().Write([]byte("q")); err != nil {
+		t.Fatalf("writing PtyWriter failed: %v", err)
+	}
+	if err := rec.SendKeys(ptyx.KeyUp); err != nil {
+		t.Fatalf("SendKeys() failed: %v", err)
+	}
+	if err := rec.Resize(90, 20); err != nil {
+		t.Fatalf("Resize() failed: %v", err)
+	}
+
+	if got := s.PtyInBuffer.String(); got != "q\x1b[A" {
+		t.Errorf("session input = %q", got)
+	}
+	want := "O 1.000000 5\nI 1.000000 1\nI 1.000000 3\nS 1.000000 SIGWINCH ROWS=20 COLS=90\n"
+	if got := timing.String(); got != want {
+		t.Errorf("timing = %q, want %q", got, want)
+	}
+	if out.String() != "hello" || in.String() != "q\x1b[A" {
+		t.Errorf("out = %q, in = %q", out.String(), in.String())
+	}
+}
@@ -0,0 +1,285 @@
+// Package typescript reads and writes the typescript and timing files
+// produced by util-linux script(1), in both the classic and the advanced
+// (multi-stream) timing formats, and records or replays ptyx sessions in
+// that format.
+package typescript
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Format int
+
+const (
+	// FormatClassic is the "delay nbytes" timing format of script -t. It
+	// carries the output stream only.
+	FormatClassic Format = iota
+	// FormatAdvanced is the "type delay data" format of script -T -m advanced,
+	// which also logs input, window size changes and session information.
+	FormatAdvanced
+)
+
+type EventType byte
+
+const (
+	EventOutput EventType = 'O'
+	EventInput  EventType = 'I'
+	EventSignal EventType = 'S'
+	EventInfo   EventType = 'H'
+)
+
+// StartPrefix begins the first line of a typescript written by script(1).
+// Reader skips that line since the timing file does not account for it.
+const StartPrefix = "Script started on "
+
+var ErrFormat = errors.New("typescript: stream not supported by classic timing format")
+
+// Event is one entry of the timing file. Data holds the logged bytes of
+// output and input events. Signal and info events carry a Name (such as
+// "SIGWINCH" or "COLUMNS") and an optional Value.
+type Event struct {
+	Type  EventType
+	Delay time.Duration
+	Data  []byte
+	Name  string
+	Value string
+}
+
+// Size returns the window size recorded by a SIGWINCH event.
+func (e Event) Size() (cols, rows int, ok bool) {
+	if e.Type != EventSignal || e.Name != "SIGWINCH" {
+		return 0, 0, false
+	}
+	for _, f := range strings.Fields(e.Value) {
+		k, v, _ := strings.Cut(f, "=")
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, false
+		}
+		switch k {
+		case "COLS":
+			cols = n
+		case "ROWS":
+			rows = n
+		}
+	}
+	return cols, rows, cols > 0 && rows > 0
+}
+
+type Writer struct {
+	mu     sync.Mutex
+	out    io.Writer
+	in     io.Writer
+	timing io.Writer
+	format Format
+	now    func() time.Time
+	last   time.Time
+}
+
+// NewWriter writes output to out, input to in and timing to timing. in may
+// be nil when input is not logged; it must be nil for FormatClassic.
+func NewWriter(out, in, timing io.Writer, format Format) (*Writer, error) {
+	return newWriter(out, in, timing, format, time.Now)
+}
+
+func newWriter(out, in, timing io.Writer, format Format, now func() time.Time) (*Writer, error) {
+	if format == FormatClassic && in != nil {
+		return nil, ErrFormat
+	}
+	return &Writer{out: out, in: in, timing: timing, format: format, now: now, last: now()}, nil
+}
+
+func (w *Writer) WriteOutput(p []byte) error { return w.writeData(EventOutput, w.out, p) }
+
+// WriteInput logs p when the writer has an input stream and is a no-op
+// otherwise.
+func (w *Writer) WriteInput(p []byte) error {
+	if w.in == nil {
+		return nil
+	}
+	return w.writeData(EventInput, w.in, p)
+}
+
+// WriteResize logs a SIGWINCH event. Classic timing files cannot hold it, so
+// it is dropped for FormatClassic.
+func (w *Writer) WriteResize(cols, rows int) error {
+	return w.writeNamed(EventSignal, "SIGWINCH", fmt.Sprintf("ROWS=%d COLS=%d", rows, cols))
+}
+
+// WriteInfo logs a header entry such as START_TIME, TERM, COLUMNS, LINES,
+// DURATION or EXIT_CODE. It is dropped for FormatClassic.
+func (w *Writer) WriteInfo(name, value string) error {
+	return w.writeNamed(EventInfo, name, value)
+}
+
+func (w *Writer) writeData(typ EventType, dst io.Writer, p []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := dst.Write(p); err != nil {
+		return err
+	}
+	delay := w.delay()
+	if w.format == FormatClassic {
+		_, err := fmt.Fprintf(w.timing, "%s %d\n", delay, len(p))
+		return err
+	}
+	_, err := fmt.Fprintf(w.timing, "%c %s %d\n", typ, delay, len(p))
+	return err
+}
+
+func (w *Writer) writeNamed(typ EventType, name, value string) error {
+	if w.format == FormatClassic {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delay := "0.000000"
+	if typ != EventInfo {
+		delay = w.delay()
+	}
+	line := fmt.Sprintf("%c %s %s", typ, delay, name)
+	if value != "" {
+		line += " " + value
+	}
+	_, err := io.WriteString(w.timing, line+"\n")
+	return err
+}
+
+func (w *Writer) delay() string {
+	t := w.now()
+	d := t.Sub(w.last)
+	w.last = t
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
+
+type Reader struct {
+	timing  *bufio.Reader
+	out     *bufio.Reader
+	in      *bufio.Reader
+	started bool
+}
+
+// NewReader reads timing entries from timing and the logged bytes from out
+// and in. in may be nil, in which case input events carry no Data. The
+// format is detected per line.
+func NewReader(timing, out, in io.Reader) *Reader {
+	r := &Reader{timing: bufio.NewReader(timing), out: bufio.NewReader(out)}
+	if in != nil {
+		r.in = bufio.NewReader(in)
+	}
+	return r
+}
+
+// Next returns the next event, or io.EOF at the end of the timing file.
+func (r *Reader) Next() (Event, error) {
+	for {
+		line, err := r.timing.ReadString('\n')
+		if line == "" && err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return r.parse(line)
+	}
+}
+
+func (r *Reader) parse(line string) (Event, error) {
+	f := strings.SplitN(line, " ", 4)
+	if len(f[0]) == 1 && !isDigit(f[0][0]) {
+		if len(f) < 3 {
+			return Event{}, fmt.Errorf("typescript: malformed timing line %q", line)
+		}
+		e := Event{Type: EventType(f[0][0])}
+		var err error
+		if e.Delay, err = parseDelay(f[1]); err != nil {
+			return Event{}, err
+		}
+		switch e.Type {
+		case EventOutput, EventInput:
+			e.Data, err = r.data(e.Type, f[2])
+			return e, err
+		case EventSignal, EventInfo:
+			e.Name = f[2]
+			if len(f) == 4 {
+				e.Value = f[3]
+			}
+			return e, nil
+		}
+		return Event{}, fmt.Errorf("typescript: unknown event type %q", f[0])
+	}
+
+	if len(f) != 2 {
+		return Event{}, fmt.Errorf("typescript: malformed timing line %q", line)
+	}
+	e := Event{Type: EventOutput}
+	var err error
+	if e.Delay, err = parseDelay(f[0]); err != nil {
+		return Event{}, err
+	}
+	e.Data, err = r.data(EventOutput, f[1])
+	return e, err
+}
+
+func (r *Reader) data(typ EventType, count string) ([]byte, error) {
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("typescript: invalid byte count %q", count)
+	}
+	src := r.out
+	if typ == EventInput {
+		// The input log is a separate file, so without it the event is
+		// still returned, just without its bytes.
+		if r.in == nil {
+			return nil, nil
+		}
+		src = r.in
+	} else if !r.started {
+		r.started = true
+		if err := skipStartLine(src); err != nil {
+			return nil, err
+		}
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(src, p); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func skipStartLine(r *bufio.Reader) error {
+	p, err := r.Peek(len(StartPrefix))
+	if err != nil || !bytes.Equal(p, []byte(StartPrefix)) {
+		return nil
+	}
+	_, err = r.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func parseDelay(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("typescript: invalid delay %q", s)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
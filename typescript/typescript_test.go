@@ -0,0 +1,178 @@
+package typescript
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakeClock(step time.Duration) func() time.Time {
+	t := time.Unix(1700000000, 0)
+	return func() time.Time {
+		t = t.Add(step)
+		return t
+	}
+}
+
+func TestWriter_Advanced(t *testing.T) {
+	var out, in, timing bytes.Buffer
+	w, err := newWriter(&out, &in, &timing, FormatAdvanced, fakeClock(250*time.Millisecond))
+	if err != nil {
+		t.Fatalf("newWriter() failed: %v", err)
+	}
+	steps := []func() error{
+		func() error { return w.WriteInfo("COLUMNS", "80") },
+		func() error { return w.WriteOutput([]byte("$ ")) },
+		func() error { return w.WriteInput([]byte("ls\r")) },
+		func() error { return w.WriteResize(100, 30) },
+		func() error { return w.WriteOutput(nil) },
+		func() error { return w.WriteInfo("EXIT_CODE", "0") },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	wantTiming := "H 0.000000 COLUMNS 80\nO 0.250000 2\nI 0.250000 3\nS 0.250000 SIGWINCH ROWS=30 COLS=100\nH 0.000000 EXIT_CODE 0\n"
+	if got := timing.String(); got != wantTiming {
+		t.Errorf("timing = %q, want %q", got, wantTiming)
+	}
+	if out.String() != "$ " || in.String() != "ls\r" {
+		t.Errorf("out = %q, in = %q", out.String(), in.String())
+	}
+}
+
+func TestWriter_Classic(t *testing.T) {
+	if _, err := NewWriter(io.Discard, io.Discard, io.Discard, FormatClassic); !errors.Is(err, ErrFormat) {
+		t.Fatalf("NewWriter() with input stream error = %v, want ErrFormat", err)
+	}
+
+	var out, timing bytes.Buffer
+	w, err := newWriter(&out, nil, &timing, FormatClassic, fakeClock(time.Second))
+	if err != nil {
+		t.Fatalf("newWriter() failed: %v", err)
+	}
+	_ = w.WriteInfo("TERM", "xterm")
+	_ = w.WriteOutput([]byte("hello"))
+	_ = w.WriteInput([]byte("ignored"))
+	_ = w.WriteResize(80, 24)
+	_ = w.WriteOutput([]byte("!"))
+
+	if got, want := timing.String(), "1.000000 5\n1.000000 1\n"; got != want {
+		t.Errorf("timing = %q, want %q", got, want)
+	}
+	if got := out.String(); got != "hello!" {
+		t.Errorf("out = %q", got)
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing string
+		out    string
+		in     string
+		want   []Event
+	}{
+		{
+			name:   "Classic",
+			timing: "0.5 3\n1.25 2\n",
+			out:    StartPrefix + "2024-01-01 [TERM=\"xterm\"]\nabcde\nScript done\n",
+			want: []Event{
+				{Type: EventOutput, Delay: 500 * time.Millisecond, Data: []byte("abc")},
+				{Type: EventOutput, Delay: 1250 * time.Millisecond, Data: []byte("de")},
+			},
+		},
+		{
+			name:   "Advanced",
+			timing: "H 0.000000 START_TIME Mon 01 Jan 2024 00:00:00 UTC\nO 0.1 2\nI 0.2 3\nS 0.3 SIGWINCH ROWS=30 COLS=100\n\nO 0.4 1\n",
+			out:    "$ x",
+			in:     "ls\r",
+			want: []Event{
+				{Type: EventInfo, Name: "START_TIME", Value: "Mon 01 Jan 2024 00:00:00 UTC"},
+				{Type: EventOutput, Delay: 100 * time.Millisecond, Data: []byte("$ ")},
+				{Type: EventInput, Delay: 200 * time.Millisecond, Data: []byte("ls\r")},
+				{Type: EventSignal, Delay: 300 * time.Millisecond, Name: "SIGWINCH", Value: "ROWS=30 COLS=100"},
+				{Type: EventOutput, Delay: 400 * time.Millisecond, Data: []byte("x")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.timing), strings.NewReader(tt.out), strings.NewReader(tt.in))
+			var got []Event
+			for {
+				e, err := r.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next() failed: %v", err)
+				}
+				got = append(got, e)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReader_NoInputStream(t *testing.T) {
+	r := NewReader(strings.NewReader("I 0.1 3\nO 0.1 1\n"), strings.NewReader("x"), nil)
+	e, err := r.Next()
+	if err != nil || e.Type != EventInput || e.Data != nil {
+		t.Fatalf("Next() = %+v, %v", e, err)
+	}
+	if e, err := r.Next(); err != nil || string(e.Data) != "x" {
+		t.Fatalf("Next() = %+v, %v", e, err)
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing string
+		out    string
+	}{
+		{"BadDelay", "abc 3\n", "xxx"},
+		{"NegativeDelay", "-1 3\n", "xxx"},
+		{"BadCount", "0.1 x\n", "xxx"},
+		{"ShortOutput", "0.1 10\n", "xxx"},
+		{"ExtraFields", "0.1 1 2\n", "xxx"},
+		{"UnknownType", "X 0.1 1\n", "xxx"},
+		{"TruncatedAdvanced", "O 0.1\n", "xxx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.timing), strings.NewReader(tt.out), nil)
+			if _, err := r.Next(); err == nil || errors.Is(err, io.EOF) {
+				t.Errorf("Next() error = %v, want a parse error", err)
+			}
+		})
+	}
+}
+
+func TestEvent_Size(t *testing.T) {
+	tests := []struct {
+		e          Event
+		cols, rows int
+		ok         bool
+	}{
+		{Event{Type: EventSignal, Name: "SIGWINCH", Value: "ROWS=24 COLS=80"}, 80, 24, true},
+		{Event{Type: EventSignal, Name: "SIGWINCH", Value: "ROWS=x COLS=80"}, 0, 0, false},
+		{Event{Type: EventSignal, Name: "SIGTERM"}, 0, 0, false},
+		{Event{Type: EventOutput}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		cols, rows, ok := tt.e.Size()
+		if cols != tt.cols || rows != tt.rows || ok != tt.ok {
+			t.Errorf("%+v.Size() = %d, %d, %v", tt.e, cols, rows, ok)
+		}
+	}
+}
@@ -0,0 +1,916 @@
+// Package vt implements a headless virtual terminal that keeps an in-memory
+// screen of whatever a program running under ptyx has drawn.
+package vt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/KennethanCeyer/ptyx"
+	"github.com/KennethanCeyer/ptyx/vtparse"
+)
+
+type Color uint32
+
+const DefaultColor Color = 0
+
+const (
+	colorIndexed = 1 << 24
+	colorRGB     = 2 << 24
+	colorKind    = 0xff << 24
+)
+
+func IndexedColor(i uint8) Color { return Color(colorIndexed | uint32(i)) }
+
+func RGBColor(r, g, b uint8) Color {
+	return Color(colorRGB | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
+}
+
+func (c Color) IsDefault() bool { return c == DefaultColor }
+
+func (c Color) Index() (uint8, bool) { return uint8(c), c&colorKind == colorIndexed }
+
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), c&colorKind == colorRGB
+}
+
+type AttrFlags uint16
+
+const (
+	Bold AttrFlags = 1 << iota
+	Faint
+	Italic
+	Underline
+	Blink
+	Reverse
+	Hidden
+	Strike
+)
+
+type Attr struct {
+	Flags AttrFlags
+	FG    Color
+	BG    Color
+}
+
+// Cell is one character cell. The right half of a wide character is a cell
+// with Width 0.
+type Cell struct {
+	Rune  rune
+	Comb  []rune
+	Width int
+	Attr  Attr
+}
+
+func (c Cell) String() string {
+	if c.Width == 0 {
+		return ""
+	}
+	if c.Rune == 0 {
+		return " "
+	}
+	return string(c.Rune) + string(c.Comb)
+}
+
+type Modes struct {
+	AppCursor      bool
+	AppKeypad      bool
+	AutoWrap       bool
+	Origin         bool
+	Insert         bool
+	CursorVisible  bool
+	AltScreen      bool
+	BracketedPaste bool
+	FocusReporting bool
+	MouseTracking  int
+	MouseSGR       bool
+}
+
+type cursor struct {
+	x, y     int
+	attr     Attr
+	wrapNext bool
+	origin   bool
+	charsets [2]bool
+	gl       int
+}
+
+type Screen struct {
+	mu sync.Mutex
+
+	cols, rows int
+	lines      [][]Cell
+	primary    [][]Cell
+	alt        [][]Cell
+	cur        cursor
+	saved      cursor
+	altSaved   cursor
+	top, bot   int
+	tabs       []bool
+	modes      Modes
+	title      string
+	last       rune
+
+	p       vtparse.Parser
+	resp    io.Writer
+	pending []byte
+}
+
+func NewScreen(cols, rows int) *Screen {
+	if cols <= 0 {
+		cols = 80
+	}
+	if rows <= 0 {
+		rows = 24
+	}
+	s := &Screen{}
+	s.reset(cols, rows)
+	return s
+}
+
+// SetResponseWriter sets where replies to device status and attribute
+// queries are written, typically the session's PtyWriter.
+func (s *Screen) SetResponseWriter(w io.Writer) {
+	s.mu.Lock()
+	s.resp = w
+	s.mu.Unlock()
+}
+
+func (s *Screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	s.p.Parse(p, s.handle)
+	resp, out := s.pending, s.resp
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(resp) > 0 && out != nil {
+		_, _ = out.Write(resp)
+	}
+	return len(p), nil
+}
+
+func (s *Screen) Resize(cols, rows int) {
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lines, s.cur.y = dropTop(s.lines, s.cur.y, rows)
+	s.lines = resizeBuffer(s.lines, cols, rows)
+	if s.primary != nil {
+		// The primary's cursor was saved in altSaved on the way to the
+		// alternate screen.
+		s.primary, s.altSaved.y = dropTop(s.primary, s.altSaved.y, rows)
+		s.primary = resizeBuffer(s.primary, cols, rows)
+	}
+	if s.alt != nil {
+		s.alt = resizeBuffer(s.alt, cols, rows)
+	}
+	s.cols, s.rows = cols, rows
+	s.top, s.bot = 0, rows-1
+	s.resetTabs()
+	s.cur = s.clamp(s.cur)
+	s.saved = s.clamp(s.saved)
+	s.altSaved = s.clamp(s.altSaved)
+}
+
+func (s *Screen) Size() (cols, rows int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cols, s.rows
+}
+
+func (s *Screen) Cell(x, y int) Cell {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if x < 0 || y < 0 || x >= s.cols || y >= s.rows {
+		return Cell{}
+	}
+	c := s.lines[y][x]
+	c.Comb = append([]rune(nil), c.Comb...)
+	return c
+}
+
+func (s *Screen) Cursor() (x, y int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cur.x, s.cur.y
+}
+
+func (s *Screen) Modes() Modes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.modes
+}
+
+func (s *Screen) Title() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.title
+}
+
+// Line returns the text of row y with trailing blanks removed.
+func (s *Screen) Line(y int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if y < 0 || y >= s.rows {
+		return ""
+	}
+	return lineString(s.lines[y])
+}
+
+func (s *Screen) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.lines))
+	for i, l := range s.lines {
+		out[i] = lineString(l)
+	}
+	return strings.Join(out, "\n")
+}
+
+func lineString(l []Cell) string {
+	var b strings.Builder
+	for _, c := range l {
+		b.WriteString(c.String())
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func (s *Screen) reset(cols, rows int) {
+	s.cols, s.rows = cols, rows
+	s.lines = newBuffer(cols, rows, Attr{})
+	s.primary, s.alt = nil, nil
+	s.cur = cursor{}
+	s.saved, s.altSaved = cursor{}, cursor{}
+	s.top, s.bot = 0, rows-1
+	s.modes = Modes{AutoWrap: true, CursorVisible: true}
+	s.resetTabs()
+}
+
+func (s *Screen) resetTabs() {
+	s.tabs = make([]bool, s.cols)
+	for i := 8; i < s.cols; i += 8 {
+		s.tabs[i] = true
+	}
+}
+
+func newBuffer(cols, rows int, a Attr) [][]Cell {
+	b := make([][]Cell, rows)
+	for i := range b {
+		b[i] = newLine(cols, a)
+	}
+	return b
+}
+
+func newLine(cols int, a Attr) []Cell {
+	l := make([]Cell, cols)
+	for i := range l {
+		l[i] = blank(a)
+	}
+	return l
+}
+
+func blank(a Attr) Cell { return Cell{Rune: ' ', Width: 1, Attr: Attr{BG: a.BG}} }
+
+// dropTop drops lines from the top of b so that row y fits in rows lines,
+// and returns y's new row.
+func dropTop(b [][]Cell, y, rows int) ([][]Cell, int) {
+	if drop := min(y-rows+1, len(b)); drop > 0 {
+		return b[drop:], y - drop
+	}
+	return b, y
+}
+
+func resizeBuffer(b [][]Cell, cols, rows int) [][]Cell {
+	if len(b) > rows {
+		b = b[:rows]
+	}
+	for len(b) < rows {
+		b = append(b, newLine(cols, Attr{}))
+	}
+	for i, l := range b {
+		switch {
+		case len(l) > cols:
+			l = l[:cols]
+			if cols > 0 && l[cols-1].Width == 2 {
+				l[cols-1] = blank(l[cols-1].Attr)
+			}
+		case len(l) < cols:
+			l = append(l, newLine(cols-len(l), Attr{})...)
+		}
+		b[i] = l
+	}
+	return b
+}
+
+func (s *Screen) clamp(c cursor) cursor {
+	c.x = min(max(c.x, 0), s.cols-1)
+	c.y = min(max(c.y, 0), s.rows-1)
+	c.wrapNext = false
+	return c
+}
+
+func (s *Screen) respond(format string, args ...any) {
+	s.pending = fmt.Appendf(s.pending, format, args...)
+}
+
+func (s *Screen) handle(e vtparse.Event) {
+	switch e.Kind {
+	case vtparse.KindPrint:
+		s.print(e.Rune)
+	case vtparse.KindExecute:
+		s.execute(e.Byte)
+	case vtparse.KindCSI:
+		s.csi(e.Params, e.Private, e.Intermediates, e.Final)
+	case vtparse.KindESC:
+		s.esc(e.Intermediates, e.Final)
+	case vtparse.KindOSC:
+		s.osc(e.Data)
+	}
+}
+
+func (s *Screen) print(r rune) {
+	w := ptyx.RuneWidth(r)
+	if w == 0 {
+		s.combine(r)
+		return
+	}
+	if s.cur.charsets[s.cur.gl] && r >= 0x5f && r <= 0x7e {
+		r = lineDrawing[r-0x5f]
+	}
+	if w > s.cols {
+		w = 1
+	}
+	if s.cur.wrapNext {
+		s.cur.x = 0
+		s.lineFeed()
+	}
+	s.cur.wrapNext = false
+	if w == 2 && s.cur.x == s.cols-1 {
+		if !s.modes.AutoWrap {
+			return
+		}
+		s.clearWide(s.lines[s.cur.y], s.cur.x)
+		s.lines[s.cur.y][s.cur.x] = blank(s.cur.attr)
+		s.cur.x = 0
+		s.lineFeed()
+	}
+
+	line := s.lines[s.cur.y]
+	if s.modes.Insert {
+		s.insertCells(w)
+	}
+	s.clearWide(line, s.cur.x)
+	if w == 2 {
+		s.clearWide(line, s.cur.x+1)
+	}
+	line[s.cur.x] = Cell{Rune: r, Width: w, Attr: s.cur.attr}
+	if w == 2 {
+		line[s.cur.x+1] = Cell{Attr: s.cur.attr}
+	}
+	s.last = r
+
+	if s.cur.x+w >= s.cols {
+		s.cur.x = s.cols - 1
+		s.cur.wrapNext = s.modes.AutoWrap
+		return
+	}
+	s.cur.x += w
+}
+
+func (s *Screen) combine(r rune) {
+	x, y := s.cur.x-1, s.cur.y
+	if s.cur.wrapNext {
+		x = s.cur.x
+	}
+	if x < 0 {
+		return
+	}
+	line := s.lines[y]
+	if line[x].Width == 0 && x > 0 {
+		x--
+	}
+	line[x].Comb = append(line[x].Comb, r)
+}
+
+func (s *Screen) clearWide(line []Cell, x int) {
+	if x >= len(line) {
+		return
+	}
+	switch line[x].Width {
+	case 0:
+		if x > 0 {
+			line[x-1] = blank(line[x-1].Attr)
+		}
+	case 2:
+		if x+1 < len(line) {
+			line[x+1] = blank(line[x+1].Attr)
+		}
+	}
+}
+
+func (s *Screen) lineFeed() {
+	switch {
+	case s.cur.y == s.bot:
+		s.scrollUp(1)
+	case s.cur.y < s.rows-1:
+		s.cur.y++
+	}
+}
+
+func (s *Screen) reverseIndex() {
+	switch {
+	case s.cur.y == s.top:
+		s.scrollDown(1)
+	case s.cur.y > 0:
+		s.cur.y--
+	}
+}
+
+func (s *Screen) scrollUp(n int) {
+	n = min(n, s.bot-s.top+1)
+	region := s.lines[s.top : s.bot+1]
+	copy(region, region[n:])
+	for i := len(region) - n; i < len(region); i++ {
+		region[i] = newLine(s.cols, s.cur.attr)
+	}
+}
+
+func (s *Screen) scrollDown(n int) {
+	n = min(n, s.bot-s.top+1)
+	region := s.lines[s.top : s.bot+1]
+	copy(region[n:], region)
+	for i := 0; i < n; i++ {
+		region[i] = newLine(s.cols, s.cur.attr)
+	}
+}
+
+func (s *Screen) insertCells(n int) {
+	line := s.lines[s.cur.y]
+	n = min(n, s.cols-s.cur.x)
+	copy(line[s.cur.x+n:], line[s.cur.x:])
+	for i := s.cur.x; i < s.cur.x+n; i++ {
+		line[i] = blank(s.cur.attr)
+	}
+}
+
+func (s *Screen) deleteCells(n int) {
+	line := s.lines[s.cur.y]
+	n = min(n, s.cols-s.cur.x)
+	copy(line[s.cur.x:], line[s.cur.x+n:])
+	for i := s.cols - n; i < s.cols; i++ {
+		line[i] = blank(s.cur.attr)
+	}
+}
+
+func (s *Screen) erase(y, from, to int) {
+	line := s.lines[y]
+	for x := max(from, 0); x < min(to, s.cols); x++ {
+		line[x] = blank(s.cur.attr)
+	}
+}
+
+func (s *Screen) execute(b byte) {
+	switch b {
+	case 0x08:
+		if s.cur.x > 0 {
+			s.cur.x--
+		}
+		s.cur.wrapNext = false
+	case 0x09:
+		s.tab(1)
+	case 0x0a, 0x0b, 0x0c:
+		s.lineFeed()
+		s.cur.wrapNext = false
+	case 0x0d:
+		s.cur.x = 0
+		s.cur.wrapNext = false
+	case 0x0e:
+		s.cur.gl = 1
+	case 0x0f:
+		s.cur.gl = 0
+	}
+}
+
+func (s *Screen) tab(n int) {
+	for ; n > 0 && s.cur.x < s.cols-1; n-- {
+		s.cur.x++
+		for s.cur.x < s.cols-1 && !s.tabs[s.cur.x] {
+			s.cur.x++
+		}
+	}
+	s.cur.wrapNext = false
+}
+
+func (s *Screen) esc(inter []byte, final byte) {
+	if len(inter) == 1 {
+		switch inter[0] {
+		case '(', ')':
+			s.cur.charsets[inter[0]-'('] = final == '0'
+		case '#':
+			if final == '8' {
+				for y := range s.lines {
+					for x := range s.lines[y] {
+						s.lines[y][x] = Cell{Rune: 'E', Width: 1}
+					}
+				}
+			}
+		}
+		return
+	}
+	if len(inter) > 0 {
+		return
+	}
+	switch final {
+	case '7':
+		s.saved = s.cur
+	case '8':
+		s.cur = s.clamp(s.saved)
+		s.modes.Origin = s.cur.origin
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.cur.x = 0
+		s.lineFeed()
+	case 'H':
+		s.tabs[s.cur.x] = true
+	case 'M':
+		s.reverseIndex()
+	case 'c':
+		resp := s.resp
+		s.reset(s.cols, s.rows)
+		s.resp = resp
+		s.title = ""
+	case '=':
+		s.modes.AppKeypad = true
+	case '>':
+		s.modes.AppKeypad = false
+	}
+	s.cur.wrapNext = false
+}
+
+func (s *Screen) osc(data []byte) {
+	cmd, arg, ok := strings.Cut(string(data), ";")
+	if !ok {
+		return
+	}
+	switch cmd {
+	case "0", "2":
+		s.title = arg
+	}
+}
+
+func param(params vtparse.Params, i, def int) int {
+	if v := params.Get(i, 0); v > 0 {
+		return v
+	}
+	return def
+}
+
+func (s *Screen) csi(params vtparse.Params, private byte, inter []byte, final byte) {
+	if private == '?' {
+		switch final {
+		case 'h', 'l':
+			for i := 0; i < params.Len(); i++ {
+				s.setPrivateMode(params.Get(i, 0), final == 'h')
+			}
+		}
+		return
+	}
+	if private != 0 {
+		return
+	}
+	if len(inter) > 0 {
+		if inter[0] == '!' && final == 'p' {
+			s.modes = Modes{AutoWrap: true, CursorVisible: true, AltScreen: s.modes.AltScreen}
+			s.cur.attr = Attr{}
+			s.top, s.bot = 0, s.rows-1
+		}
+		return
+	}
+
+	n := param(params, 0, 1)
+	switch final {
+	case '@':
+		s.insertCells(n)
+	case 'A':
+		s.cur.y = max(s.cur.y-n, s.upperBound())
+	case 'B', 'e':
+		s.cur.y = min(s.cur.y+n, s.lowerBound())
+	case 'C', 'a':
+		s.cur.x = min(s.cur.x+n, s.cols-1)
+	case 'D':
+		s.cur.x = max(s.cur.x-n, 0)
+	case 'E':
+		s.cur.y = min(s.cur.y+n, s.lowerBound())
+		s.cur.x = 0
+	case 'F':
+		s.cur.y = max(s.cur.y-n, s.upperBound())
+		s.cur.x = 0
+	case 'G', '`':
+		s.cur.x = min(n-1, s.cols-1)
+	case 'H', 'f':
+		s.moveTo(param(params, 1, 1)-1, n-1)
+	case 'I':
+		s.tab(n)
+	case 'J':
+		s.eraseDisplay(param(params, 0, 0))
+	case 'K':
+		switch param(params, 0, 0) {
+		case 0:
+			s.erase(s.cur.y, s.cur.x, s.cols)
+		case 1:
+			s.erase(s.cur.y, 0, s.cur.x+1)
+		case 2:
+			s.erase(s.cur.y, 0, s.cols)
+		}
+	case 'L', 'M':
+		if s.cur.y < s.top || s.cur.y > s.bot {
+			break
+		}
+		top := s.top
+		s.top = s.cur.y
+		if final == 'L' {
+			s.scrollDown(n)
+		} else {
+			s.scrollUp(n)
+		}
+		s.top = top
+		s.cur.x = 0
+	case 'P':
+		s.deleteCells(n)
+	case 'S':
+		s.scrollUp(n)
+	case 'T':
+		s.scrollDown(n)
+	case 'X':
+		s.erase(s.cur.y, s.cur.x, s.cur.x+n)
+	case 'Z':
+		for ; n > 0 && s.cur.x > 0; n-- {
+			s.cur.x--
+			for s.cur.x > 0 && !s.tabs[s.cur.x] {
+				s.cur.x--
+			}
+		}
+	case 'b':
+		if s.last != 0 {
+			for i := 0; i < n; i++ {
+				s.print(s.last)
+			}
+		}
+		return
+	case 'c':
+		if param(params, 0, 0) == 0 {
+			s.respond("\x1b[?1;2c")
+		}
+	case 'd':
+		s.moveTo(s.cur.x, n-1)
+	case 'g':
+		switch param(params, 0, 0) {
+		case 0:
+			s.tabs[s.cur.x] = false
+		case 3:
+			clear(s.tabs)
+		}
+	case 'h', 'l':
+		for i := 0; i < params.Len(); i++ {
+			if params.Get(i, 0) == 4 {
+				s.modes.Insert = final == 'h'
+			}
+		}
+	case 'm':
+		s.sgr(params)
+	case 'n':
+		switch param(params, 0, 0) {
+		case 5:
+			s.respond("\x1b[0n")
+		case 6:
+			y := s.cur.y
+			if s.modes.Origin {
+				y -= s.top
+			}
+			s.respond("\x1b[%d;%dR", y+1, s.cur.x+1)
+		}
+	case 'r':
+		top, bot := param(params, 0, 1)-1, param(params, 1, s.rows)-1
+		if bot >= s.rows {
+			bot = s.rows - 1
+		}
+		if top < bot {
+			s.top, s.bot = top, bot
+			s.moveTo(0, 0)
+		}
+	case 's':
+		s.saved = s.cur
+	case 'u':
+		s.cur = s.clamp(s.saved)
+	}
+	s.cur.wrapNext = false
+}
+
+func (s *Screen) upperBound() int {
+	if s.cur.y >= s.top {
+		return s.top
+	}
+	return 0
+}
+
+func (s *Screen) lowerBound() int {
+	if s.cur.y <= s.bot {
+		return s.bot
+	}
+	return s.rows - 1
+}
+
+func (s *Screen) moveTo(x, y int) {
+	if s.modes.Origin {
+		y = min(max(y+s.top, s.top), s.bot)
+	}
+	s.cur.x = min(max(x, 0), s.cols-1)
+	s.cur.y = min(max(y, 0), s.rows-1)
+	s.cur.wrapNext = false
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.erase(s.cur.y, s.cur.x, s.cols)
+		for y := s.cur.y + 1; y < s.rows; y++ {
+			s.erase(y, 0, s.cols)
+		}
+	case 1:
+		for y := 0; y < s.cur.y; y++ {
+			s.erase(y, 0, s.cols)
+		}
+		s.erase(s.cur.y, 0, s.cur.x+1)
+	case 2, 3:
+		for y := 0; y < s.rows; y++ {
+			s.erase(y, 0, s.cols)
+		}
+	}
+}
+
+func (s *Screen) setPrivateMode(m int, on bool) {
+	switch m {
+	case 1:
+		s.modes.AppCursor = on
+	case 6:
+		s.modes.Origin = on
+		s.cur.origin = on
+		s.moveTo(0, 0)
+	case 7:
+		s.modes.AutoWrap = on
+	case 25:
+		s.modes.CursorVisible = on
+	case 47:
+		s.switchScreen(on, false)
+	case 1047:
+		s.switchScreen(on, !on)
+	case 1048:
+		if on {
+			s.altSaved = s.cur
+		} else {
+			s.cur = s.clamp(s.altSaved)
+		}
+	case 1049:
+		if on {
+			s.altSaved = s.cur
+		}
+		s.switchScreen(on, on)
+		if !on {
+			s.cur = s.clamp(s.altSaved)
+		}
+	case 9, 1000, 1002, 1003:
+		if on {
+			s.modes.MouseTracking = m
+		} else if s.modes.MouseTracking == m {
+			s.modes.MouseTracking = 0
+		}
+	case 1004:
+		s.modes.FocusReporting = on
+	case 1006:
+		s.modes.MouseSGR = on
+	case 2004:
+		s.modes.BracketedPaste = on
+	}
+}
+
+// switchScreen switches between the primary and alternate buffers. The
+// alternate buffer keeps its contents between switches unless clearAlt is
+// set: like xterm, 1049 clears it on the way in and 1047 on the way out.
+func (s *Screen) switchScreen(alt, clearAlt bool) {
+	if alt == s.modes.AltScreen {
+		return
+	}
+	s.modes.AltScreen = alt
+	if alt {
+		s.primary, s.lines, s.alt = s.lines, s.alt, nil
+		if s.lines == nil || clearAlt {
+			s.lines = newBuffer(s.cols, s.rows, Attr{})
+		}
+		return
+	}
+	s.alt, s.lines, s.primary = s.lines, s.primary, nil
+	if clearAlt {
+		s.alt = nil
+	}
+}
+
+func (s *Screen) sgr(params vtparse.Params) {
+	n := params.Len()
+	if n == 0 {
+		s.cur.attr = Attr{}
+		return
+	}
+	a := &s.cur.attr
+	for i := 0; i < n; i++ {
+		switch p := params.Get(i, 0); {
+		case p == 0:
+			*a = Attr{}
+		case p == 1:
+			a.Flags |= Bold
+		case p == 2:
+			a.Flags |= Faint
+		case p == 3:
+			a.Flags |= Italic
+		case p == 4 || p == 21:
+			a.Flags |= Underline
+		case p == 5 || p == 6:
+			a.Flags |= Blink
+		case p == 7:
+			a.Flags |= Reverse
+		case p == 8:
+			a.Flags |= Hidden
+		case p == 9:
+			a.Flags |= Strike
+		case p == 22:
+			a.Flags &^= Bold | Faint
+		case p == 23:
+			a.Flags &^= Italic
+		case p == 24:
+			a.Flags &^= Underline
+		case p == 25:
+			a.Flags &^= Blink
+		case p == 27:
+			a.Flags &^= Reverse
+		case p == 28:
+			a.Flags &^= Hidden
+		case p == 29:
+			a.Flags &^= Strike
+		case p >= 30 && p <= 37:
+			a.FG = IndexedColor(uint8(p - 30))
+		case p == 38:
+			a.FG, i = extColor(params, i)
+		case p == 39:
+			a.FG = DefaultColor
+		case p >= 40 && p <= 47:
+			a.BG = IndexedColor(uint8(p - 40))
+		case p == 48:
+			a.BG, i = extColor(params, i)
+		case p == 49:
+			a.BG = DefaultColor
+		case p >= 90 && p <= 97:
+			a.FG = IndexedColor(uint8(p - 90 + 8))
+		case p >= 100 && p <= 107:
+			a.BG = IndexedColor(uint8(p - 100 + 8))
+		}
+	}
+}
+
+// extColor decodes an extended color starting at top-level parameter i,
+// accepting both the "38;5;n" and the "38:5:n" / "38:2::r:g:b" forms.
+func extColor(params vtparse.Params, i int) (Color, int) {
+	if sub := params.Sub(i); len(sub) > 0 {
+		switch {
+		case sub[0] == 5 && len(sub) >= 2:
+			return IndexedColor(uint8(sub[1])), i
+		case sub[0] == 2 && len(sub) >= 5:
+			rgb := sub[len(sub)-3:]
+			return RGBColor(uint8(rgb[0]), uint8(rgb[1]), uint8(rgb[2])), i
+		case sub[0] == 2 && len(sub) == 4:
+			return RGBColor(uint8(sub[1]), uint8(sub[2]), uint8(sub[3])), i
+		}
+		return DefaultColor, i
+	}
+	n := params.Len()
+	switch params.Get(i+1, -1) {
+	case 5:
+		if i+2 < n {
+			return IndexedColor(uint8(params.Get(i+2, 0))), i + 2
+		}
+	case 2:
+		if i+4 < n {
+			return RGBColor(uint8(params.Get(i+2, 0)), uint8(params.Get(i+3, 0)), uint8(params.Get(i+4, 0))), i + 4
+		}
+	}
+	return DefaultColor, n
+}
+
+var lineDrawing = [32]rune{
+	' ', '◆', '▒', '␉', '␌', '␍', '␊', '°', '±', '␤', '␋', '┘', '┐', '┌', '└', '┼',
+	'⎺', '⎻', '─', '⎼', '⎽', '├', '┤', '┴', '┬', '│', '≤', '≥', 'π', '≠', '£', '·',
+}
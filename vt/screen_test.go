@@ -0,0 +1,253 @@
+package vt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func write(s *Screen, chunks ...string) {
+	for _, c := range chunks {
+		_, _ = s.Write([]byte(c))
+	}
+}
+
+func TestScreen_Text(t *testing.T) {
+	tests := []struct {
+		name   string
+		cols   int
+		rows   int
+		input  []string
+		want   string
+		cx, cy int
+	}{
+		{"Plain", 10, 3, []string{"hello"}, "hello\n\n", 5, 0},
+		{"CRLF", 10, 3, []string{"ab\r\ncd"}, "ab\ncd\n", 2, 1},
+		{"Autowrap", 4, 3, []string{"abcdef"}, "abcd\nef\n", 2, 1},
+		{"PendingWrapAtEdge", 4, 3, []string{"abcd"}, "abcd\n\n", 3, 0},
+		{"ScrollAtBottom", 5, 2, []string{"1\r\n2\r\n3"}, "2\n3", 1, 1},
+		{"Backspace", 10, 1, []string{"abc\bX"}, "abX", 3, 0},
+		{"Tab", 20, 1, []string{"a\tb"}, "a       b", 9, 0},
+		{"CUP", 10, 3, []string{"\x1b[2;4Hx"}, "\n   x\n", 4, 1},
+		{"CursorMoves", 10, 3, []string{"\x1b[3;5H\x1b[2A\x1b[3D\x1b[Cx"}, "  x\n\n", 3, 0},
+		{"EraseLine", 10, 1, []string{"abcdef\x1b[4G\x1b[K"}, "abc", 3, 0},
+		{"EraseLineStart", 10, 1, []string{"abcdef\x1b[3G\x1b[1K"}, "   def", 2, 0},
+		{"EraseDisplay", 5, 3, []string{"a\r\nb\r\nc\x1b[2J"}, "\n\n", 1, 2},
+		{"EraseChars", 10, 1, []string{"abcdef\x1b[2G\x1b[3X"}, "a   ef", 1, 0},
+		{"InsertDeleteChars", 10, 1, []string{"abcd\x1b[2G\x1b[2@XY\x1b[P"}, "aXYcd", 3, 0},
+		{"InsertLine", 5, 3, []string{"1\r\n2\r\n3\x1b[2;1H\x1b[L"}, "1\n\n2", 0, 1},
+		{"DeleteLine", 5, 3, []string{"1\r\n2\r\n3\x1b[1;1H\x1b[M"}, "2\n3\n", 0, 0},
+		{"ScrollRegion", 5, 4, []string{"a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[3;1H\nX"}, "a\nc\nX\nd", 1, 2},
+		{"ReverseIndex", 5, 3, []string{"a\r\nb\x1b[H\x1bM"}, "\na\nb", 0, 0},
+		{"SaveRestore", 10, 2, []string{"ab\x1b7\x1b[2;5Hz\x1b8c"}, "abc\n    z", 3, 0},
+		{"Wide", 6, 2, []string{"a世b"}, "a世b\n", 4, 0},
+		{"WideWrapsAtEdge", 3, 2, []string{"ab世"}, "ab\n世", 2, 1},
+		{"WideOverwrite", 6, 1, []string{"世\x1b[2Gx"}, " x", 2, 0},
+		{"Combining", 6, 1, []string{"éx"}, "éx", 2, 0},
+		{"SplitUTF8", 6, 1, []string{"\xe4\xb8", "\x96"}, "世", 2, 0},
+		{"SplitCSI", 10, 2, []string{"\x1b", "[2", ";3", "Hx"}, "\n  x", 3, 1},
+		{"OSCIgnored", 10, 1, []string{"\x1b]8;;http://x\x1b\\link\x1b]8;;\x07"}, "link", 4, 0},
+		{"DCSIgnored", 10, 1, []string{"\x1bPq#0;2;0;0;0\x1b\\ok"}, "ok", 2, 0},
+		{"LineDrawing", 10, 1, []string{"\x1b(0lqk\x1b(Bq"}, "┌─┐q", 4, 0},
+		{"Repeat", 10, 1, []string{"a\x1b[3b"}, "aaaa", 4, 0},
+		{"NoAutowrap", 4, 2, []string{"\x1b[?7labcdef"}, "abcf\n", 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScreen(tt.cols, tt.rows)
+			write(s, tt.input...)
+			if got := s.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if x, y := s.Cursor(); x != tt.cx || y != tt.cy {
+				t.Errorf("Cursor() = (%d,%d), want (%d,%d)", x, y, tt.cx, tt.cy)
+			}
+		})
+	}
+}
+
+func TestScreen_SGR(t *testing.T) {
+	s := NewScreen(10, 1)
+	write(s, "\x1b[1;4;31ma\x1b[22;38;5;200;48;2;1;2;3mb\x1b[0;97mc\x1b[md")
+
+	tests := []struct {
+		x    int
+		want Attr
+	}{
+		{0, Attr{Flags: Bold | Underline, FG: IndexedColor(1)}},
+		{1, Attr{Flags: Underline, FG: IndexedColor(200), BG: RGBColor(1, 2, 3)}},
+		{2, Attr{FG: IndexedColor(15)}},
+		{3, Attr{}},
+	}
+	for _, tt := range tests {
+		if got := s.Cell(tt.x, 0).Attr; got != tt.want {
+			t.Errorf("Cell(%d).Attr = %+v, want %+v", tt.x, got, tt.want)
+		}
+	}
+
+	write(s, "\x1b[H\x1b[0;38:2::9:8:7;48:5:42mz")
+	if got, want := s.Cell(0, 0).Attr, (Attr{FG: RGBColor(9, 8, 7), BG: IndexedColor(42)}); got != want {
+		t.Errorf("colon SGR Attr = %+v, want %+v", got, want)
+	}
+
+	if r, g, b, ok := RGBColor(1, 2, 3).RGB(); !ok || r != 1 || g != 2 || b != 3 {
+		t.Errorf("RGB() = %d,%d,%d,%v", r, g, b, ok)
+	}
+	if i, ok := IndexedColor(7).Index(); !ok || i != 7 {
+		t.Errorf("Index() = %d,%v", i, ok)
+	}
+	if _, ok := DefaultColor.Index(); ok || !DefaultColor.IsDefault() {
+		t.Error("DefaultColor should not be indexed")
+	}
+}
+
+func TestScreen_AltScreen(t *testing.T) {
+	s := NewScreen(10, 3)
+	write(s, "shell$ ", "\x1b[?1049h\x1b[H\x1b[2Jtui")
+
+	if !s.Modes().AltScreen {
+		t.Fatal("AltScreen mode not set")
+	}
+	if got := s.Line(0); got != "tui" {
+		t.Errorf("alt Line(0) = %q, want %q", got, "tui")
+	}
+
+	write(s, "\x1b[?1049l")
+	if s.Modes().AltScreen {
+		t.Fatal("AltScreen mode still set")
+	}
+	if got := s.Line(0); got != "shell$" {
+		t.Errorf("primary Line(0) = %q, want %q", got, "shell$")
+	}
+	if x, y := s.Cursor(); x != 7 || y != 0 {
+		t.Errorf("Cursor() = (%d,%d), want (7,0)", x, y)
+	}
+}
+
+func TestScreen_AltScreenClearing(t *testing.T) {
+	tests := []struct {
+		name    string
+		leave   string
+		reenter string
+		want    string
+	}{
+		{"47Keeps", "\x1b[?47h\x1b[Halt\x1b[?47l", "\x1b[?47h", "alt"},
+		{"1047ClearsOnLeave", "\x1b[?1047h\x1b[Halt\x1b[?1047l", "\x1b[?47h", ""},
+		{"1049KeepsOnLeave", "\x1b[?1049h\x1b[Halt\x1b[?1049l", "\x1b[?47h", "alt"},
+		{"1049ClearsOnEnter", "\x1b[?47h\x1b[Halt\x1b[?47l", "\x1b[?1049h", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScreen(10, 3)
+			write(s, "shell", tt.leave)
+			if got := s.Line(0); got != "shell" {
+				t.Fatalf("primary Line(0) = %q, want %q", got, "shell")
+			}
+			write(s, tt.reenter)
+			if got := s.Line(0); got != tt.want {
+				t.Errorf("alt Line(0) = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreen_ResizeOnAltScreen(t *testing.T) {
+	s := NewScreen(5, 3)
+	write(s, "1\r\n2\r\n3", "\x1b[?1049h")
+	s.Resize(5, 2)
+	write(s, "\x1b[?1049l")
+
+	if got := s.Line(0) + "\n" + s.Line(1); got != "2\n3" {
+		t.Errorf("primary lines = %q, want %q", got, "2\n3")
+	}
+	if x, y := s.Cursor(); x != 1 || y != 1 {
+		t.Errorf("Cursor() = (%d,%d), want (1,1)", x, y)
+	}
+}
+
+func TestScreen_Modes(t *testing.T) {
+	s := NewScreen(10, 3)
+	write(s, "\x1b[?1h\x1b=\x1b[?25l\x1b[?2004h\x1b[?1002h\x1b[?1006h\x1b[?1004h")
+	want := Modes{
+		AppCursor:      true,
+		AppKeypad:      true,
+		AutoWrap:       true,
+		BracketedPaste: true,
+		FocusReporting: true,
+		MouseTracking:  1002,
+		MouseSGR:       true,
+	}
+	if got := s.Modes(); got != want {
+		t.Errorf("Modes() = %+v, want %+v", got, want)
+	}
+
+	write(s, "\x1bc")
+	if got := s.Modes(); got != (Modes{AutoWrap: true, CursorVisible: true}) {
+		t.Errorf("Modes() after RIS = %+v", got)
+	}
+}
+
+func TestScreen_Title(t *testing.T) {
+	s := NewScreen(10, 1)
+	write(s, "\x1b]0;first\x07", "\x1b]2;sec", "ond\x1b\\")
+	if got := s.Title(); got != "second" {
+		t.Errorf("Title() = %q, want %q", got, "second")
+	}
+}
+
+func TestScreen_Responses(t *testing.T) {
+	var resp bytes.Buffer
+	s := NewScreen(10, 5)
+	s.SetResponseWriter(&resp)
+	write(s, "\x1b[3;4H\x1b[6n\x1b[5n\x1b[c")
+
+	if got, want := resp.String(), "\x1b[3;4R\x1b[0n\x1b[?1;2c"; got != want {
+		t.Errorf("responses = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_Resize(t *testing.T) {
+	s := NewScreen(6, 4)
+	write(s, "1\r\n2\r\n3\r\n4abcde")
+
+	s.Resize(3, 2)
+	if cols, rows := s.Size(); cols != 3 || rows != 2 {
+		t.Fatalf("Size() = %d,%d, want 3,2", cols, rows)
+	}
+	if got := s.String(); got != "3\n4ab" {
+		t.Errorf("String() after shrink = %q", got)
+	}
+	if x, y := s.Cursor(); x != 2 || y != 1 {
+		t.Errorf("Cursor() after shrink = (%d,%d), want (2,1)", x, y)
+	}
+
+	s.Resize(5, 3)
+	write(s, "\x1b[3;5Hz")
+	if got := s.String(); got != "3\n4ab\n    z" {
+		t.Errorf("String() after grow = %q", got)
+	}
+
+	s.Resize(0, 10)
+	if cols, rows := s.Size(); cols != 5 || rows != 3 {
+		t.Errorf("Resize(0, 10) changed size to %d,%d", cols, rows)
+	}
+}
+
+func TestScreen_CellOutOfRange(t *testing.T) {
+	s := NewScreen(2, 2)
+	if c := s.Cell(5, 5); c.Rune != 0 || c.Width != 0 {
+		t.Errorf("Cell out of range = %+v, want zero", c)
+	}
+	if got := s.Line(-1); got != "" {
+		t.Errorf("Line(-1) = %q", got)
+	}
+}
+
+func TestScreen_Garbage(t *testing.T) {
+	s := NewScreen(20, 2)
+	write(s, "\x1b[999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999;999m")
+	write(s, "\xff\x1b[?1;>2hok", "\x1b[1\x18x")
+	if got := s.Line(0); !strings.HasSuffix(got, "okx") {
+		t.Errorf("Line(0) = %q, want suffix %q", got, "okx")
+	}
+}
@@ -0,0 +1,68 @@
+package vt
+
+import (
+	"context"
+	"io"
+
+	"github.com/KennethanCeyer/ptyx"
+)
+
+const (
+	DefaultCols = 80
+	DefaultRows = 24
+)
+
+// Terminal couples a Session with a Screen that is fed from the session's
+// PtyReader. The PtyReader is owned by the Terminal and must not be read
+// elsewhere.
+type Terminal struct {
+	ptyx.Session
+	screen *Screen
+	done   chan struct{}
+}
+
+func Spawn(ctx context.Context, opts ptyx.SpawnOpts) (*Terminal, error) {
+	if opts.Cols <= 0 || opts.Rows <= 0 {
+		opts.Cols, opts.Rows = DefaultCols, DefaultRows
+	}
+	s, err := ptyx.Spawn(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Attach(s, opts.Cols, opts.Rows), nil
+}
+
+func Attach(s ptyx.Session, cols, rows int) *Terminal {
+	t := &Terminal{
+		Session: s,
+		screen:  NewScreen(cols, rows),
+		done:    make(chan struct{}),
+	}
+	t.screen.SetResponseWriter(s.PtyWriter())
+	go func() {
+		defer close(t.done)
+		_, _ = io.Copy(t.screen, s.PtyReader())
+	}()
+	return t
+}
+
+func (t *Terminal) Screen() *Screen { return t.screen }
+
+// Done is closed once the session's output stream has ended.
+func (t *Terminal) Done() <-chan struct{} { return t.done }
+
+func (t *Terminal) Resize(cols, rows int) error {
+	if err := t.Session.Resize(cols, rows); err != nil {
+		return err
+	}
+	t.screen.Resize(cols, rows)
+	return nil
+}
+
+func (t *Terminal) ResizeWithPixels(cols, rows, xpixel, ypixel int) error {
+	if err := t.Session.ResizeWithPixels(cols, rows, xpixel, ypixel); err != nil {
+		return err
+	}
+	t.screen.Resize(cols, rows)
+	return nil
+}
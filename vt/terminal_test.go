@@ -0,0 +1,78 @@
+package vt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/ptyx"
+	"github.com/KennethanCeyer/ptyx/testptyx"
+)
+
+func TestAttach(t *testing.T) {
+	pr, pw := io.Pipe()
+	s := testptyx.NewMockSession("")
+	s.PtyOutReader = pr
+
+	term := Attach(s, 20, 4)
+	_, _ = pw.Write([]byte("\x1b[2;3Hhi\x1b[6n"))
+	pw.Close()
+
+	select {
+	case <-term.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done() not closed after output ended")
+	}
+
+	if got := term.Screen().Line(1); got != "  hi" {
+		t.Errorf("Line(1) = %q, want %q", got, "  hi")
+	}
+	if got := s.PtyInBuffer.String(); got != "\x1b[2;5R" {
+		t.Errorf("response = %q, want %q", got, "\x1b[2;5R")
+	}
+
+	if err := term.Resize(30, 5); err != nil {
+		t.Fatalf("Resize() failed: %v", err)
+	}
+	if cols, rows := term.Screen().Size(); cols != 30 || rows != 5 {
+		t.Errorf("Screen().Size() = %d,%d, want 30,5", cols, rows)
+	}
+	if err := term.ResizeWithPixels(40, 6, 400, 120); err != nil {
+		t.Fatalf("ResizeWithPixels() failed: %v", err)
+	}
+	if cols, rows := term.Screen().Size(); cols != 40 || rows != 6 {
+		t.Errorf("Screen().Size() = %d,%d, want 40,6", cols, rows)
+	}
+}
+
+func TestSpawn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	term, err := Spawn(context.Background(), ptyx.SpawnOpts{Prog: "sh", Args: []string{"-c", `printf 'a\033[2;1Hb'`}})
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			t.Skipf("could not find 'sh': %v", err)
+		}
+		t.Fatalf("Spawn failed: %v", err)
+	}
+	defer term.Close()
+
+	if cols, rows := term.Screen().Size(); cols != DefaultCols || rows != DefaultRows {
+		t.Errorf("Size() = %d,%d, want default", cols, rows)
+	}
+	_ = term.Wait()
+
+	select {
+	case <-term.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("output did not end")
+	}
+	if got := term.Screen().Line(0) + "|" + term.Screen().Line(1); got != "a|b" {
+		t.Errorf("screen = %q, want %q", got, "a|b")
+	}
+}
@@ -0,0 +1,554 @@
+// Package vtparse implements Paul Williams' DEC-compatible state machine for
+// parsing terminal output. It keeps all state between calls to Parse, so
+// input may be split at arbitrary byte boundaries.
+package vtparse
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultMaxData = 64 * 1024
+	MaxParams      = 32
+	maxParamValue  = 65535
+)
+
+type Kind int
+
+const (
+	KindPrint Kind = iota + 1
+	KindExecute
+	KindCSI
+	KindESC
+	KindOSC
+	KindDCS
+	KindSOS
+	KindPM
+	KindAPC
+)
+
+var kindNames = [...]string{
+	KindPrint:   "Print",
+	KindExecute: "Execute",
+	KindCSI:     "CSI",
+	KindESC:     "ESC",
+	KindOSC:     "OSC",
+	KindDCS:     "DCS",
+	KindSOS:     "SOS",
+	KindPM:      "PM",
+	KindAPC:     "APC",
+}
+
+func (k Kind) String() string {
+	if k > 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+type Param struct {
+	// Value is -1 when the parameter was omitted.
+	Value int
+	// Sub reports that the parameter was introduced by ':' and belongs to
+	// the preceding top-level parameter.
+	Sub bool
+}
+
+type Params []Param
+
+func (p Params) Len() int {
+	n := 0
+	for _, v := range p {
+		if !v.Sub {
+			n++
+		}
+	}
+	return n
+}
+
+func (p Params) index(i int) int {
+	for j, v := range p {
+		if v.Sub {
+			continue
+		}
+		if i == 0 {
+			return j
+		}
+		i--
+	}
+	return -1
+}
+
+// Get returns top-level parameter i, or def if it is absent or omitted.
+func (p Params) Get(i, def int) int {
+	j := p.index(i)
+	if j < 0 || p[j].Value < 0 {
+		return def
+	}
+	return p[j].Value
+}
+
+// Sub returns the ':'-separated values following top-level parameter i.
+func (p Params) Sub(i int) []int {
+	j := p.index(i)
+	if j < 0 {
+		return nil
+	}
+	var out []int
+	for _, v := range p[j+1:] {
+		if !v.Sub {
+			break
+		}
+		out = append(out, v.Value)
+	}
+	return out
+}
+
+func (p Params) String() string {
+	var b strings.Builder
+	for i, v := range p {
+		if i > 0 {
+			if v.Sub {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte(';')
+			}
+		}
+		if v.Value >= 0 {
+			b.WriteString(strconv.Itoa(v.Value))
+		}
+	}
+	return b.String()
+}
+
+// Event is a single parser action. Slices alias parser buffers and are only
+// valid until the callback returns.
+type Event struct {
+	Kind          Kind
+	Rune          rune
+	Byte          byte
+	Private       byte
+	Params        Params
+	Intermediates []byte
+	Final         byte
+	Data          []byte
+}
+
+// Sequence re-encodes the event in its 7-bit form.
+func (e Event) Sequence() string {
+	switch e.Kind {
+	case KindPrint:
+		return string(e.Rune)
+	case KindExecute:
+		return string(rune(e.Byte))
+	case KindCSI:
+		return "\x1b[" + e.body()
+	case KindESC:
+		return "\x1b" + string(e.Intermediates) + string(rune(e.Final))
+	case KindOSC:
+		return "\x1b]" + string(e.Data) + "\x1b\\"
+	case KindDCS:
+		return "\x1bP" + e.body() + string(e.Data) + "\x1b\\"
+	case KindSOS:
+		return "\x1bX" + string(e.Data) + "\x1b\\"
+	case KindPM:
+		return "\x1b^" + string(e.Data) + "\x1b\\"
+	case KindAPC:
+		return "\x1b_" + string(e.Data) + "\x1b\\"
+	}
+	return ""
+}
+
+func (e Event) body() string {
+	var b strings.Builder
+	if e.Private != 0 {
+		b.WriteByte(e.Private)
+	}
+	b.WriteString(e.Params.String())
+	b.Write(e.Intermediates)
+	b.WriteByte(e.Final)
+	return b.String()
+}
+
+type state int
+
+const (
+	sGround state = iota
+	sEscape
+	sEscapeIntermediate
+	sCSIEntry
+	sCSIParam
+	sCSIIntermediate
+	sCSIIgnore
+	sDCSEntry
+	sDCSParam
+	sDCSIntermediate
+	sDCSPassthrough
+	sDCSIgnore
+	sOSCString
+	sSOSPMAPCString
+)
+
+// Parser is a streaming escape sequence parser. The zero value decodes
+// UTF-8 text and is ready to use.
+type Parser struct {
+	// MaxData bounds the payload kept for OSC, DCS, SOS, PM and APC
+	// strings; excess bytes are dropped. Zero means DefaultMaxData.
+	MaxData int
+	// Legacy8Bit treats bytes 0x80-0x9f as C1 controls and 0xa0-0xff as
+	// Latin-1 instead of decoding UTF-8.
+	Legacy8Bit bool
+
+	state      state
+	params     Params
+	digits     bool
+	sawSep     bool
+	nextSub    bool
+	private    byte
+	inter      []byte
+	final      byte
+	data       []byte
+	utf        []byte
+	strKind    Kind
+	fromString bool
+}
+
+func (p *Parser) Reset() {
+	p.state = sGround
+	p.utf = p.utf[:0]
+	p.fromString = false
+	p.clear()
+}
+
+func (p *Parser) clear() {
+	p.params = p.params[:0]
+	p.digits, p.sawSep, p.nextSub = false, false, false
+	p.private = 0
+	p.inter = p.inter[:0]
+	p.final = 0
+}
+
+// Parse consumes b and calls emit for every completed event.
+func (p *Parser) Parse(b []byte, emit func(Event)) {
+	for _, c := range b {
+		p.advance(c, emit)
+	}
+}
+
+func (p *Parser) maxData() int {
+	if p.MaxData > 0 {
+		return p.MaxData
+	}
+	return DefaultMaxData
+}
+
+func (p *Parser) advance(c byte, emit func(Event)) {
+	if len(p.utf) > 0 {
+		if p.state == sGround && c&0xc0 == 0x80 {
+			p.utf = append(p.utf, c)
+			if utf8.FullRune(p.utf) {
+				r, _ := utf8.DecodeRune(p.utf)
+				p.utf = p.utf[:0]
+				p.printRune(r, emit)
+			}
+			return
+		}
+		p.utf = p.utf[:0]
+		emit(Event{Kind: KindPrint, Rune: utf8.RuneError})
+	}
+
+	switch {
+	case c == 0x18 || c == 0x1a:
+		p.fromString = false
+		p.state = sGround
+		emit(Event{Kind: KindExecute, Byte: c})
+		return
+	case c == 0x1b:
+		p.leave(emit)
+		p.clear()
+		p.state = sEscape
+		return
+	case c >= 0x80 && c <= 0x9f && p.Legacy8Bit:
+		p.leave(emit)
+		p.c1(c, emit)
+		return
+	}
+
+	switch p.state {
+	case sGround:
+		p.ground(c, emit)
+	case sEscape:
+		p.escape(c, emit)
+	case sEscapeIntermediate:
+		switch {
+		case c < 0x20:
+			emit(Event{Kind: KindExecute, Byte: c})
+		case c <= 0x2f:
+			p.inter = append(p.inter, c)
+		case c <= 0x7e:
+			p.escDispatch(c, emit)
+		}
+	case sCSIEntry, sCSIParam:
+		switch {
+		case c < 0x20:
+			emit(Event{Kind: KindExecute, Byte: c})
+		case c <= 0x2f:
+			p.inter = append(p.inter, c)
+			p.state = sCSIIntermediate
+		case c <= 0x3b:
+			p.param(c)
+			p.state = sCSIParam
+		case c <= 0x3f:
+			if p.state == sCSIParam {
+				p.state = sCSIIgnore
+				return
+			}
+			p.private = c
+			p.state = sCSIParam
+		case c <= 0x7e:
+			p.dispatch(KindCSI, c, emit)
+			p.state = sGround
+		}
+	case sCSIIntermediate:
+		switch {
+		case c < 0x20:
+			emit(Event{Kind: KindExecute, Byte: c})
+		case c <= 0x2f:
+			p.inter = append(p.inter, c)
+		case c <= 0x3f:
+			p.state = sCSIIgnore
+		case c <= 0x7e:
+			p.dispatch(KindCSI, c, emit)
+			p.state = sGround
+		}
+	case sCSIIgnore:
+		switch {
+		case c < 0x20:
+			emit(Event{Kind: KindExecute, Byte: c})
+		case c >= 0x40 && c <= 0x7e:
+			p.state = sGround
+		}
+	case sDCSEntry, sDCSParam:
+		switch {
+		case c < 0x20:
+		case c <= 0x2f:
+			p.inter = append(p.inter, c)
+			p.state = sDCSIntermediate
+		case c <= 0x3b:
+			p.param(c)
+			p.state = sDCSParam
+		case c <= 0x3f:
+			if p.state == sDCSParam {
+				p.state = sDCSIgnore
+				return
+			}
+			p.private = c
+			p.state = sDCSParam
+		case c <= 0x7e:
+			p.hook(c)
+		}
+	case sDCSIntermediate:
+		switch {
+		case c < 0x20:
+		case c <= 0x2f:
+			p.inter = append(p.inter, c)
+		case c <= 0x3f:
+			p.state = sDCSIgnore
+		case c <= 0x7e:
+			p.hook(c)
+		}
+	case sDCSPassthrough, sSOSPMAPCString:
+		if c != 0x7f {
+			p.put(c)
+		}
+	case sDCSIgnore:
+	case sOSCString:
+		switch {
+		case c == 0x07:
+			p.leave(emit)
+			p.state = sGround
+		case c >= 0x20:
+			p.put(c)
+		}
+	}
+}
+
+func (p *Parser) ground(c byte, emit func(Event)) {
+	switch {
+	case c < 0x20:
+		emit(Event{Kind: KindExecute, Byte: c})
+	case c < 0x7f:
+		emit(Event{Kind: KindPrint, Rune: rune(c)})
+	case c == 0x7f:
+	case p.Legacy8Bit:
+		emit(Event{Kind: KindPrint, Rune: rune(c)})
+	default:
+		p.utf = append(p.utf, c)
+		if utf8.FullRune(p.utf) {
+			r, _ := utf8.DecodeRune(p.utf)
+			p.utf = p.utf[:0]
+			p.printRune(r, emit)
+		}
+	}
+}
+
+func (p *Parser) printRune(r rune, emit func(Event)) {
+	if r >= 0x80 && r <= 0x9f {
+		emit(Event{Kind: KindExecute, Byte: byte(r)})
+		return
+	}
+	emit(Event{Kind: KindPrint, Rune: r})
+}
+
+func (p *Parser) escape(c byte, emit func(Event)) {
+	switch {
+	case c < 0x20:
+		emit(Event{Kind: KindExecute, Byte: c})
+	case c <= 0x2f:
+		p.inter = append(p.inter, c)
+		p.state = sEscapeIntermediate
+	case c == '[':
+		p.state = sCSIEntry
+	case c == ']':
+		p.startString(KindOSC)
+		p.state = sOSCString
+	case c == 'P':
+		p.state = sDCSEntry
+	case c == 'X':
+		p.startString(KindSOS)
+		p.state = sSOSPMAPCString
+	case c == '^':
+		p.startString(KindPM)
+		p.state = sSOSPMAPCString
+	case c == '_':
+		p.startString(KindAPC)
+		p.state = sSOSPMAPCString
+	case c <= 0x7e:
+		p.escDispatch(c, emit)
+	}
+}
+
+func (p *Parser) escDispatch(c byte, emit func(Event)) {
+	p.state = sGround
+	if p.fromString && c == '\\' && len(p.inter) == 0 {
+		p.fromString = false
+		return
+	}
+	p.fromString = false
+	emit(Event{Kind: KindESC, Intermediates: p.inter, Final: c})
+}
+
+func (p *Parser) c1(c byte, emit func(Event)) {
+	p.clear()
+	switch c {
+	case 0x90:
+		p.state = sDCSEntry
+	case 0x9b:
+		p.state = sCSIEntry
+	case 0x9c:
+		p.state = sGround
+	case 0x9d:
+		p.startString(KindOSC)
+		p.state = sOSCString
+	case 0x98:
+		p.startString(KindSOS)
+		p.state = sSOSPMAPCString
+	case 0x9e:
+		p.startString(KindPM)
+		p.state = sSOSPMAPCString
+	case 0x9f:
+		p.startString(KindAPC)
+		p.state = sSOSPMAPCString
+	default:
+		p.state = sGround
+		emit(Event{Kind: KindExecute, Byte: c})
+	}
+}
+
+func (p *Parser) param(c byte) {
+	if c == ';' || c == ':' {
+		if !p.digits {
+			p.addParam(-1)
+		}
+		p.digits = false
+		p.sawSep = true
+		p.nextSub = c == ':'
+		return
+	}
+	if !p.digits {
+		p.digits = p.addParam(0)
+		if !p.digits {
+			return
+		}
+	}
+	last := &p.params[len(p.params)-1]
+	last.Value = min(last.Value*10+int(c-'0'), maxParamValue)
+}
+
+func (p *Parser) addParam(v int) bool {
+	if len(p.params) >= MaxParams {
+		return false
+	}
+	p.params = append(p.params, Param{Value: v, Sub: p.nextSub})
+	return true
+}
+
+func (p *Parser) finishParams() {
+	if !p.digits && p.sawSep {
+		p.addParam(-1)
+	}
+}
+
+func (p *Parser) dispatch(k Kind, final byte, emit func(Event)) {
+	p.finishParams()
+	emit(Event{
+		Kind:          k,
+		Private:       p.private,
+		Params:        p.params,
+		Intermediates: p.inter,
+		Final:         final,
+	})
+}
+
+func (p *Parser) hook(final byte) {
+	p.finishParams()
+	p.final = final
+	p.startString(KindDCS)
+	p.state = sDCSPassthrough
+}
+
+func (p *Parser) startString(k Kind) {
+	p.strKind = k
+	p.data = p.data[:0]
+}
+
+func (p *Parser) put(c byte) {
+	if len(p.data) < p.maxData() {
+		p.data = append(p.data, c)
+	}
+}
+
+// leave runs the exit action of string states, dispatching the collected
+// payload when the string is terminated by ESC, ST or BEL.
+func (p *Parser) leave(emit func(Event)) {
+	switch p.state {
+	case sOSCString, sSOSPMAPCString:
+		emit(Event{Kind: p.strKind, Data: p.data})
+	case sDCSPassthrough:
+		emit(Event{
+			Kind:          KindDCS,
+			Private:       p.private,
+			Params:        p.params,
+			Intermediates: p.inter,
+			Final:         p.final,
+			Data:          p.data,
+		})
+	case sDCSIgnore:
+	default:
+		p.fromString = false
+		return
+	}
+	p.fromString = true
+}
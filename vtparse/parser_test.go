@@ -0,0 +1,167 @@
+package vtparse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func collect(p *Parser, chunks ...string) []string {
+	var out []string
+	for _, c := range chunks {
+		p.Parse([]byte(c), func(e Event) {
+			out = append(out, describe(e))
+		})
+	}
+	return out
+}
+
+func describe(e Event) string {
+	switch e.Kind {
+	case KindPrint:
+		return fmt.Sprintf("Print(%q)", e.Rune)
+	case KindExecute:
+		return fmt.Sprintf("Execute(%#x)", e.Byte)
+	case KindOSC, KindSOS, KindPM, KindAPC:
+		return fmt.Sprintf("%s(%q)", e.Kind, e.Data)
+	case KindDCS:
+		return fmt.Sprintf("DCS(%q, %q)", e.Sequence()[2:len(e.Sequence())-2-len(e.Data)], e.Data)
+	}
+	return fmt.Sprintf("%s(%q)", e.Kind, e.Sequence())
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []string
+	}{
+		{"Text", []string{"hi"}, []string{"Print('h')", "Print('i')"}},
+		{"C0", []string{"a\r\n\x07"}, []string{"Print('a')", "Execute(0xd)", "Execute(0xa)", "Execute(0x7)"}},
+		{"DELIgnored", []string{"\x7f"}, nil},
+		{"CSI", []string{"\x1b[1;31m"}, []string{`CSI("\x1b[1;31m")`}},
+		{"CSIPrivate", []string{"\x1b[?25l"}, []string{`CSI("\x1b[?25l")`}},
+		{"CSIIntermediate", []string{"\x1b[2 q"}, []string{`CSI("\x1b[2 q")`}},
+		{"CSIOmittedParams", []string{"\x1b[;5H"}, []string{`CSI("\x1b[;5H")`}},
+		{"CSITrailingSeparator", []string{"\x1b[1;m"}, []string{`CSI("\x1b[1;m")`}},
+		{"CSISubparams", []string{"\x1b[38:2::10:20:30m"}, []string{`CSI("\x1b[38:2::10:20:30m")`}},
+		{"CSIExecuteInside", []string{"\x1b[1\n;2H"}, []string{"Execute(0xa)", `CSI("\x1b[1;2H")`}},
+		{"CSIIgnoreMisplacedPrivate", []string{"\x1b[1?2hX"}, []string{"Print('X')"}},
+		{"CSISplit", []string{"\x1b", "[", "3", "8;5", ";1", "2", "m"}, []string{`CSI("\x1b[38;5;12m")`}},
+		{"CSICancel", []string{"\x1b[12\x18x"}, []string{"Execute(0x18)", "Print('x')"}},
+		{"ESC", []string{"\x1b7\x1b(0\x1b#8"}, []string{`ESC("\x1b7")`, `ESC("\x1b(0")`, `ESC("\x1b#8")`}},
+		{"ESCRestartsSequence", []string{"\x1b[1\x1b[2J"}, []string{`CSI("\x1b[2J")`}},
+		{"OSCBEL", []string{"\x1b]0;title\x07"}, []string{`OSC("0;title")`}},
+		{"OSCST", []string{"\x1b]8;;http://x\x1b", "\\link"}, []string{`OSC("8;;http://x")`, "Print('l')", "Print('i')", "Print('n')", "Print('k')"}},
+		{"OSCUTF8", []string{"\x1b]2;日本\x07"}, []string{`OSC("2;日本")`}},
+		{"OSCCancel", []string{"\x1b]0;x\x18"}, []string{"Execute(0x18)"}},
+		{"OSCThenESC", []string{"\x1b]0;x\x1b7"}, []string{`OSC("0;x")`, `ESC("\x1b7")`}},
+		{"DCS", []string{"\x1bP1$r0m\x1b\\"}, []string{`DCS("1$r", "0m")`}},
+		{"DCSPrivate", []string{"\x1bP>|xterm(1)\x1b\\"}, []string{`DCS(">|", "xterm(1)")`}},
+		{"DCSSplit", []string{"\x1bPq#0", ";2;0\x1b", "\\"}, []string{`DCS("q", "#0;2;0")`}},
+		{"APC", []string{"\x1b_Gf=100\x1b\\"}, []string{`APC("Gf=100")`}},
+		{"PM", []string{"\x1b^priv\x1b\\"}, []string{`PM("priv")`}},
+		{"SOS", []string{"\x1bXstr\x1b\\"}, []string{`SOS("str")`}},
+		{"UTF8Split", []string{"\xe4", "\xb8", "\x96"}, []string{"Print('世')"}},
+		{"UTF8Invalid", []string{"\xe4a"}, []string{"Print('�')", "Print('a')"}},
+		{"UTF8C1", []string{"\xc2\x9b"}, []string{"Execute(0x9b)"}},
+		{"StrayST", []string{"\x1b\\"}, []string{`ESC("\x1b\\")`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Parser
+			got := collect(&p, tt.chunks...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ByteAtATime(t *testing.T) {
+	input := "a\x1b[1;2H\x1b]0;t\x07\x1bP$qm\x1b\\\x1b_x\x1b\\é\x1bM"
+	var whole, split Parser
+	want := collect(&whole, input)
+
+	var chunks []string
+	for i := 0; i < len(input); i++ {
+		chunks = append(chunks, input[i:i+1])
+	}
+	if got := collect(&split, chunks...); !reflect.DeepEqual(got, want) {
+		t.Errorf("byte-at-a-time = %q, want %q", got, want)
+	}
+}
+
+func TestParser_Legacy8Bit(t *testing.T) {
+	p := Parser{Legacy8Bit: true}
+	got := collect(&p, "\x9b2J\x9d0;t\x9c\x84\xe9")
+	want := []string{`CSI("\x1b[2J")`, `OSC("0;t")`, "Execute(0x84)", "Print('é')"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestParser_Limits(t *testing.T) {
+	p := Parser{MaxData: 4}
+	if got := collect(&p, "\x1b]0123456789\x07"); !reflect.DeepEqual(got, []string{`OSC("0123")`}) {
+		t.Errorf("events = %q", got)
+	}
+
+	var n int
+	var q Parser
+	q.Parse([]byte("\x1b["+strings.Repeat("1;", 40)+"99999999m"), func(e Event) {
+		n = len(e.Params)
+		if v := e.Params.Get(0, 0); v != 1 {
+			t.Errorf("Params.Get(0) = %d", v)
+		}
+	})
+	if n != MaxParams {
+		t.Errorf("len(Params) = %d, want %d", n, MaxParams)
+	}
+
+	q.Parse([]byte("\x1b[99999999m"), func(e Event) {
+		if v := e.Params.Get(0, 0); v != maxParamValue {
+			t.Errorf("clamped param = %d, want %d", v, maxParamValue)
+		}
+	})
+}
+
+func TestParams(t *testing.T) {
+	var got Params
+	var p Parser
+	p.Parse([]byte("\x1b[1;38:2::10:20:30;;4m"), func(e Event) {
+		got = append(Params(nil), e.Params...)
+	})
+
+	if n := got.Len(); n != 4 {
+		t.Errorf("Len() = %d, want 4", n)
+	}
+	if v := got.Get(1, 0); v != 38 {
+		t.Errorf("Get(1) = %d, want 38", v)
+	}
+	if v := got.Get(2, 7); v != 7 {
+		t.Errorf("Get(2) = %d, want default 7", v)
+	}
+	if v := got.Get(3, 0); v != 4 {
+		t.Errorf("Get(3) = %d, want 4", v)
+	}
+	if v := got.Get(9, -5); v != -5 {
+		t.Errorf("Get(9) = %d, want -5", v)
+	}
+	if sub := got.Sub(1); !reflect.DeepEqual(sub, []int{2, -1, 10, 20, 30}) {
+		t.Errorf("Sub(1) = %v", sub)
+	}
+	if sub := got.Sub(0); sub != nil {
+		t.Errorf("Sub(0) = %v, want nil", sub)
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	if got := KindCSI.String(); got != "CSI" {
+		t.Errorf("KindCSI.String() = %q", got)
+	}
+	if got := Kind(99).String(); got != "Kind(99)" {
+		t.Errorf("Kind(99).String() = %q", got)
+	}
+}